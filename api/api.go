@@ -9,9 +9,36 @@ import (
 	"git.wisehodl.dev/jay/aicli/config"
 )
 
+// resolveModel selects the provider for a model entry and returns its
+// configuration with the bare model name.
+func resolveModel(cfg config.ConfigData, entry string) (config.ConfigData, string) {
+	mcfg, model, name := cfg.ForModel(entry)
+	if cfg.Verbose {
+		fmt.Fprintf(os.Stderr, "[verbose] Model %s: provider %s (%s, %s)\n", model, providerLabel(name), mcfg.Protocol, mcfg.URL)
+	}
+	return mcfg, model
+}
+
+// providerLabel names the provider of an entry, "default" for the top-level settings.
+func providerLabel(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return name
+}
+
+// logAnswered reports which provider answered in verbose mode.
+func logAnswered(cfg config.ConfigData, entry string) {
+	if cfg.Verbose {
+		_, model, name := cfg.ForModel(entry)
+		fmt.Fprintf(os.Stderr, "[verbose] Answered by %s via provider %s\n", model, providerLabel(name))
+	}
+}
+
 // tryModel attempts a single model request through the complete pipeline:
-// payload construction, HTTP execution, and response parsing.
-func tryModel(cfg config.ConfigData, model string, query string) (string, error) {
+// provider selection, payload construction, HTTP execution, and response parsing.
+func tryModel(cfg config.ConfigData, entry string, query string) (string, error) {
+	cfg, model := resolveModel(cfg, entry)
 	payload := buildPayload(cfg, model, query)
 
 	if cfg.Verbose {
@@ -19,7 +46,7 @@ func tryModel(cfg config.ConfigData, model string, query string) (string, error)
 		fmt.Fprintf(os.Stderr, "[verbose] Request payload: %s\n", string(payloadJSON))
 	}
 
-	body, err := executeHTTP(cfg, payload)
+	body, err := executeHTTP(cfg, endpointURL(cfg, model), payload)
 	if err != nil {
 		return "", err
 	}
@@ -37,11 +64,14 @@ func tryModel(cfg config.ConfigData, model string, query string) (string, error)
 }
 
 // SendChatRequest sends a query to the configured model with automatic fallback.
-// Returns the response content, the model name that succeeded, total duration, and any error.
-// On failure, attempts each fallback model in sequence until one succeeds or all fail.
+// Each model entry may name a provider as "provider:model".
+// Returns the response content, the model entry that succeeded, total duration, and any error.
+// On failure, attempts each fallback model in sequence until one succeeds, all fail,
+// or a failure's class is not in cfg.FallbackOn. The error is then a *ChainError.
 func SendChatRequest(cfg config.ConfigData, query string) (string, string, time.Duration, error) {
-	models := append([]string{cfg.Model}, cfg.FallbackModels...)
+	models := cfg.ModelEntries()
 	start := time.Now()
+	chain := &ChainError{}
 
 	for i, model := range models {
 		if !cfg.Quiet && i > 0 {
@@ -50,13 +80,96 @@ func SendChatRequest(cfg config.ConfigData, query string) (string, string, time.
 
 		response, err := tryModel(cfg, model, query)
 		if err == nil {
+			logAnswered(cfg, model)
 			return response, model, time.Since(start), nil
 		}
 
+		if !recordFailure(cfg, chain, models[i:], err) {
+			break
+		}
+	}
+
+	return "", "", time.Since(start), chain
+}
+
+// recordFailure adds the failure of remaining[0] to chain and reports whether
+// to fall back to the next model.
+func recordFailure(cfg config.ConfigData, chain *ChainError, remaining []string, err error) bool {
+	class := classifyError(err)
+	chain.Failures = append(chain.Failures, ModelError{Model: remaining[0], Class: class, Err: err})
+
+	if !cfg.Quiet {
+		fmt.Fprintf(os.Stderr, "Model %s failed: %v\n", remaining[0], err)
+	}
+
+	if len(remaining) > 1 && !shouldFallback(cfg, class, remaining[0], remaining[1]) {
+		chain.Skipped = remaining[1:]
 		if !cfg.Quiet {
-			fmt.Fprintf(os.Stderr, "Model %s failed: %v\n", model, err)
+			fmt.Fprintf(os.Stderr, "Not falling back on %s errors\n", class)
+		}
+		return false
+	}
+	return true
+}
+
+// tryModelStream attempts a single streaming request, writing content to sink
+// as it arrives. The returned bool reports whether sink received Begin, after
+// which the attempt can no longer be retried against another model.
+func tryModelStream(cfg config.ConfigData, entry string, query string, sink StreamSink) (string, bool, error) {
+	cfg, model := resolveModel(cfg, entry)
+	payload := buildPayload(cfg, model, query)
+
+	if cfg.Verbose {
+		payloadJSON, _ := json.Marshal(payload)
+		fmt.Fprintf(os.Stderr, "[verbose] Request payload: %s\n", string(payloadJSON))
+	}
+
+	resp, err := sendRequest(cfg, endpointURL(cfg, model), payload)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+
+	if err := sink.Begin(entry); err != nil {
+		return "", true, err
+	}
+
+	response, err := readStream(resp.Body, cfg.Protocol, sink)
+	if err != nil {
+		return response, true, err
+	}
+
+	return response, true, nil
+}
+
+// StreamChatRequest sends a streaming query with automatic fallback, writing
+// content to sink as it arrives. Returns the complete response, the model name
+// that succeeded, total duration, and any error. Fallback only applies to
+// models that fail before streaming begins, under the same policy as
+// SendChatRequest.
+func StreamChatRequest(cfg config.ConfigData, query string, sink StreamSink) (string, string, time.Duration, error) {
+	models := cfg.ModelEntries()
+	start := time.Now()
+	chain := &ChainError{}
+
+	for i, model := range models {
+		if !cfg.Quiet && i > 0 {
+			fmt.Fprintf(os.Stderr, "Model %s failed, trying %s...\n", models[i-1], model)
+		}
+
+		response, started, err := tryModelStream(cfg, model, query, sink)
+		if err == nil {
+			logAnswered(cfg, model)
+			return response, model, time.Since(start), nil
+		}
+		if started {
+			return response, model, time.Since(start), fmt.Errorf("stream from %s interrupted: %w", model, err)
+		}
+
+		if !recordFailure(cfg, chain, models[i:], err) {
+			break
 		}
 	}
 
-	return "", "", time.Since(start), fmt.Errorf("all models failed")
+	return "", "", time.Since(start), chain
 }
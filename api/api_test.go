@@ -2,10 +2,14 @@ package api
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"syscall"
 	"testing"
 	"time"
 
@@ -24,6 +28,9 @@ func (t *sequenceTransport) RoundTrip(*http.Request) (*http.Response, error) {
 	}
 	resp := t.responses[t.index]
 	t.index++
+	if resp == nil {
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	}
 	return resp, nil
 }
 
@@ -85,12 +92,7 @@ func TestTryModel(t *testing.T) {
 				response: tt.mockResp,
 				err:      tt.mockErr,
 			}
-			oldClient := httpClient
-			httpClient = &http.Client{
-				Timeout:   5 * time.Minute,
-				Transport: transport,
-			}
-			defer func() { httpClient = oldClient }()
+			useTransport(t, transport)
 
 			got, err := tryModel(tt.cfg, tt.model, tt.query)
 			if tt.wantErr {
@@ -170,6 +172,24 @@ func TestSendChatRequest(t *testing.T) {
 				assert.Contains(t, stderr, "trying gpt-3.5")
 			},
 		},
+		{
+			name: "anthropic fallback succeeds",
+			cfg: config.ConfigData{
+				Protocol:       config.ProtocolAnthropic,
+				URL:            "https://api.example.com",
+				APIKey:         "sk-ant-test",
+				Model:          "claude-opus-4-1",
+				FallbackModels: []string{"claude-sonnet-4-5"},
+				Quiet:          true,
+			},
+			query: "test",
+			mockResp: []*http.Response{
+				makeResponse(529, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`),
+				makeResponse(200, `{"content":[{"type":"text","text":"anthropic response"}]}`),
+			},
+			wantResponse: "anthropic response",
+			wantModel:    "claude-sonnet-4-5",
+		},
 		{
 			name: "all models fail",
 			cfg: config.ConfigData{
@@ -185,12 +205,74 @@ func TestSendChatRequest(t *testing.T) {
 				makeResponse(500, `{"error":"error2"}`),
 			},
 			wantErr:     true,
-			errContains: "all models failed",
+			errContains: "all models failed: gpt-4 (server): HTTP 500",
 			checkStderr: func(t *testing.T, stderr string) {
 				assert.Contains(t, stderr, "Model gpt-4 failed")
 				assert.Contains(t, stderr, "Model gpt-3.5 failed")
 			},
 		},
+		{
+			name: "auth error skips fallback",
+			cfg: config.ConfigData{
+				Protocol:       config.ProtocolOpenAI,
+				URL:            "https://api.example.com",
+				APIKey:         "sk-bad",
+				Model:          "gpt-4",
+				FallbackModels: []string{"gpt-3.5", "gpt-4o"},
+				FallbackOn:     []config.ErrorClass{config.ErrorServer, config.ErrorRateLimit},
+			},
+			query: "test",
+			mockResp: []*http.Response{
+				makeResponse(401, `{"error":"invalid key"}`),
+			},
+			wantErr:     true,
+			errContains: "gpt-4 (auth): HTTP 401",
+			checkStderr: func(t *testing.T, stderr string) {
+				assert.Contains(t, stderr, "Not falling back on auth errors")
+				assert.NotContains(t, stderr, "trying gpt-3.5")
+			},
+		},
+		{
+			name: "auth error falls back to another provider",
+			cfg: config.ConfigData{
+				Protocol:       config.ProtocolOpenAI,
+				URL:            "https://api.example.com",
+				APIKey:         "sk-bad",
+				Model:          "gpt-4",
+				FallbackModels: []string{"other:gpt-4o"},
+				FallbackOn:     []config.ErrorClass{config.ErrorServer},
+				Quiet:          true,
+				Providers: map[string]config.ProviderConfig{
+					"other": {Protocol: config.ProtocolOpenAI, URL: "https://other.example.com", APIKey: "sk-other"},
+				},
+			},
+			query: "test",
+			mockResp: []*http.Response{
+				makeResponse(401, `{"error":"invalid key"}`),
+				makeResponse(200, `{"choices":[{"message":{"content":"response"}}]}`),
+			},
+			wantResponse: "response",
+			wantModel:    "other:gpt-4o",
+		},
+		{
+			name: "parse error falls back when allowed",
+			cfg: config.ConfigData{
+				Protocol:       config.ProtocolOpenAI,
+				URL:            "https://api.example.com",
+				APIKey:         "sk-test",
+				Model:          "gpt-4",
+				FallbackModels: []string{"gpt-3.5"},
+				FallbackOn:     []config.ErrorClass{config.ErrorParse},
+				Quiet:          true,
+			},
+			query: "test",
+			mockResp: []*http.Response{
+				makeResponse(200, `{"choices":[]}`),
+				makeResponse(200, `{"choices":[{"message":{"content":"response"}}]}`),
+			},
+			wantResponse: "response",
+			wantModel:    "gpt-3.5",
+		},
 		{
 			name: "quiet mode suppresses progress",
 			cfg: config.ConfigData{
@@ -218,12 +300,7 @@ func TestSendChatRequest(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			transport := &sequenceTransport{responses: tt.mockResp}
 
-			oldClient := httpClient
-			httpClient = &http.Client{
-				Timeout:   5 * time.Minute,
-				Transport: transport,
-			}
-			defer func() { httpClient = oldClient }()
+			useTransport(t, transport)
 
 			var stderr string
 			var response string
@@ -261,3 +338,47 @@ func TestSendChatRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestSendChatRequestProviders(t *testing.T) {
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":"model is loading"}`))
+	}))
+	defer local.Close()
+
+	var gotBody map[string]interface{}
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer sk-openai", r.Header.Get("Authorization"))
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"choices":[{"message":{"content":"remote response"}}]}`))
+	}))
+	defer remote.Close()
+
+	cfg := config.ConfigData{
+		Protocol:       config.ProtocolOpenAI,
+		URL:            "https://unused.example.com",
+		APIKey:         "sk-default",
+		Model:          "ollama:llama3",
+		FallbackModels: []string{"openai:gpt-4o-mini"},
+		Verbose:        true,
+		Providers: map[string]config.ProviderConfig{
+			"ollama": {Protocol: config.ProtocolOllamaChat, URL: local.URL, Auth: config.AuthNone},
+			"openai": {Protocol: config.ProtocolOpenAI, URL: remote.URL, APIKey: "sk-openai"},
+		},
+	}
+
+	var response, model string
+	var err error
+	stderr := captureStderr(func() {
+		response, model, _, err = SendChatRequest(cfg, "test")
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "remote response", response)
+	assert.Equal(t, "openai:gpt-4o-mini", model)
+	assert.Equal(t, "gpt-4o-mini", gotBody["model"])
+	assert.Contains(t, stderr, "Model ollama:llama3 failed")
+	assert.Contains(t, stderr, "provider ollama (ollama-chat, "+local.URL+")")
+	assert.Contains(t, stderr, "Answered by gpt-4o-mini via provider openai")
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"git.wisehodl.dev/jay/aicli/config"
+	"git.wisehodl.dev/jay/aicli/provider"
+)
+
+// authorize sends cfg.APIKey the way cfg.Auth selects, then applies the
+// custom headers, which may override anything set before them.
+func authorize(req *http.Request, p provider.Provider, cfg config.ConfigData) {
+	p.Headers(req.Header)
+
+	switch cfg.Auth {
+	case config.AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+cfg.APIKey)
+	case config.AuthHeader:
+		req.Header.Set(cfg.AuthName, cfg.APIKey)
+	case config.AuthQuery:
+		q := req.URL.Query()
+		q.Set(cfg.AuthName, cfg.APIKey)
+		req.URL.RawQuery = q.Encode()
+	case config.AuthNone:
+	default:
+		p.Authorize(req.Header, cfg.APIKey)
+	}
+
+	for name, value := range cfg.Headers {
+		req.Header.Set(name, value)
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"git.wisehodl.dev/jay/aicli/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.ConfigData
+		wantQuery string
+		want      http.Header
+	}{
+		{
+			name: "protocol default",
+			cfg:  config.ConfigData{Protocol: config.ProtocolAnthropic, APIKey: "key"},
+			want: http.Header{
+				"X-Api-Key":         {"key"},
+				"Anthropic-Version": {"2023-06-01"},
+			},
+		},
+		{
+			name: "bearer overrides protocol scheme",
+			cfg:  config.ConfigData{Protocol: config.ProtocolAnthropic, APIKey: "key", Auth: config.AuthBearer},
+			want: http.Header{
+				"Authorization":     {"Bearer key"},
+				"Anthropic-Version": {"2023-06-01"},
+			},
+		},
+		{
+			name: "custom header",
+			cfg:  config.ConfigData{APIKey: "key", Auth: config.AuthHeader, AuthName: "api-key"},
+			want: http.Header{"Api-Key": {"key"}},
+		},
+		{
+			name:      "query parameter",
+			cfg:       config.ConfigData{APIKey: "key", Auth: config.AuthQuery, AuthName: "key"},
+			wantQuery: "key=key&v=1",
+			want:      http.Header{},
+		},
+		{
+			name: "none",
+			cfg:  config.ConfigData{APIKey: "ignored", Auth: config.AuthNone},
+			want: http.Header{},
+		},
+		{
+			name: "custom headers applied last",
+			cfg: config.ConfigData{
+				APIKey:  "key",
+				Headers: map[string]string{"Openai-Organization": "org-1", "Authorization": "Token abc"},
+			},
+			want: http.Header{
+				"Authorization":       {"Token abc"},
+				"Openai-Organization": {"org-1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "https://api.example.com/chat?v=1", nil)
+			authorize(req, lookupProvider(tt.cfg.Protocol), tt.cfg)
+
+			assert.Equal(t, tt.want, req.Header)
+			if tt.wantQuery != "" {
+				assert.Equal(t, tt.wantQuery, req.URL.RawQuery)
+			}
+		})
+	}
+}
+
+func TestSendRequestRedactsQueryKey(t *testing.T) {
+	transport := &mockRoundTripper{err: errors.New("connection refused")}
+	useTransport(t, transport)
+
+	cfg := config.ConfigData{
+		URL:      "https://api.example.com/chat",
+		APIKey:   "secret-key",
+		Auth:     config.AuthQuery,
+		AuthName: "key",
+	}
+
+	_, err := sendRequest(cfg, cfg.URL, map[string]interface{}{})
+
+	assert.Error(t, err)
+	assert.Equal(t, "secret-key", transport.request.URL.Query().Get("key"))
+	assert.NotContains(t, err.Error(), "secret-key")
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"git.wisehodl.dev/jay/aicli/config"
+)
+
+// newHTTPClient builds the client used for a request. Tests replace it to
+// inject a transport.
+var newHTTPClient = buildHTTPClient
+
+// buildHTTPClient applies the proxy, TLS and timeout settings from cfg to a
+// copy of the default transport. A unix:// URL dials its socket instead.
+// The timeout covers the whole request, or in stream mode the wait for the
+// response to start.
+func buildHTTPClient(cfg config.ConfigData) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{
+		Timeout:   cfg.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	transport.DialContext = dialer.DialContext
+
+	if socket, _, ok := splitUnixURL(cfg.URL); ok {
+		dialUnix(transport, dialer, socket)
+	} else if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := buildTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	// A streamed answer may take longer than the timeout as a whole, so it
+	// bounds the wait for headers here and each gap between chunks in
+	// sendRequest instead
+	if cfg.Stream {
+		transport.ResponseHeaderTimeout = cfg.Timeout
+		return &http.Client{Transport: transport}, nil
+	}
+	return &http.Client{Timeout: cfg.Timeout, Transport: transport}, nil
+}
+
+// buildTLSConfig returns nil when cfg sets neither a CA bundle nor a client
+// certificate. A CA bundle is added to the system roots, not used instead.
+func buildTLSConfig(cfg config.ConfigData) (*tls.Config, error) {
+	if cfg.CACert == "" && cfg.ClientCert == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{}
+
+	if cfg.CACert != "" {
+		pem, err := os.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("read CA bundle: no certificates found in %s", cfg.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
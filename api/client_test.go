@@ -0,0 +1,225 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"git.wisehodl.dev/jay/aicli/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeClientCert creates a self-signed client certificate and key in dir and
+// returns their paths with the parsed certificate.
+func writeClientCert(t *testing.T, dir string) (string, string, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "aicli-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certPath := filepath.Join(dir, "client.pem")
+	keyPath := filepath.Join(dir, "client-key.pem")
+	os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+
+	return certPath, keyPath, cert
+}
+
+// writeServerCA saves the test server's certificate as a PEM bundle.
+func writeServerCA(t *testing.T, dir string, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(dir, "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0644))
+	return path
+}
+
+func okHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+}
+
+func TestBuildHTTPClient(t *testing.T) {
+	cfg := config.ConfigData{
+		Proxy:          "http://proxy.internal:3128",
+		ConnectTimeout: 5 * time.Second,
+		Timeout:        time.Minute,
+	}
+
+	client, err := buildHTTPClient(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, client.Timeout)
+
+	transport := client.Transport.(*http.Transport)
+	req, _ := http.NewRequest("POST", "https://api.example.com", nil)
+	proxyURL, err := transport.Proxy(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://proxy.internal:3128", proxyURL.String())
+}
+
+func TestBuildHTTPClientStream(t *testing.T) {
+	client, err := buildHTTPClient(config.ConfigData{Stream: true, Timeout: time.Minute})
+	assert.NoError(t, err)
+	assert.Zero(t, client.Timeout)
+	assert.Equal(t, time.Minute, client.Transport.(*http.Transport).ResponseHeaderTimeout)
+}
+
+func TestBuildHTTPClientErrors(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "bundle.pem")
+	os.WriteFile(notPEM, []byte("not a certificate"), 0644)
+
+	tests := []struct {
+		name        string
+		cfg         config.ConfigData
+		errContains string
+	}{
+		{
+			name:        "missing CA bundle",
+			cfg:         config.ConfigData{CACert: filepath.Join(dir, "missing.pem")},
+			errContains: "read CA bundle",
+		},
+		{
+			name:        "CA bundle without certificates",
+			cfg:         config.ConfigData{CACert: notPEM},
+			errContains: "no certificates found",
+		},
+		{
+			name:        "bad client key pair",
+			cfg:         config.ConfigData{ClientCert: notPEM, ClientKey: notPEM},
+			errContains: "load client certificate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildHTTPClient(tt.cfg)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestCACertTrustsGateway(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(okHandler))
+	defer server.Close()
+
+	cfg := config.ConfigData{URL: server.URL, APIKey: "sk-test"}
+
+	_, err := tryModel(cfg, "gpt-4", "hi")
+	assert.Error(t, err, "untrusted certificate should fail")
+
+	cfg.CACert = writeServerCA(t, t.TempDir(), server)
+	got, err := tryModel(cfg, "gpt-4", "hi")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", got)
+}
+
+func TestClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath, clientCert := writeClientCert(t, dir)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(okHandler))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	cfg := config.ConfigData{
+		URL:    server.URL,
+		APIKey: "sk-test",
+		CACert: writeServerCA(t, dir, server),
+	}
+
+	_, err := tryModel(cfg, "gpt-4", "hi")
+	assert.Error(t, err, "missing client certificate should fail")
+
+	cfg.ClientCert = certPath
+	cfg.ClientKey = keyPath
+	got, err := tryModel(cfg, "gpt-4", "hi")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", got)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		okHandler(w, r)
+	}))
+	defer server.Close()
+
+	cfg := config.ConfigData{URL: server.URL, APIKey: "sk-test", Timeout: 50 * time.Millisecond}
+	_, err := tryModel(cfg, "gpt-4", "hi")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Client.Timeout")
+}
+
+// chunkHandler writes chunks with a pause before each, flushing as it goes.
+func chunkHandler(pauses ...time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for _, pause := range pauses {
+			time.Sleep(pause)
+			w.Write([]byte("data: chunk\n\n"))
+			w.(http.Flusher).Flush()
+		}
+	}
+}
+
+func TestStreamTimeout(t *testing.T) {
+	t.Run("long stream outlives the timeout", func(t *testing.T) {
+		server := httptest.NewServer(chunkHandler(40*time.Millisecond, 40*time.Millisecond, 40*time.Millisecond, 40*time.Millisecond))
+		defer server.Close()
+
+		cfg := config.ConfigData{URL: server.URL, APIKey: "sk-test", Stream: true, Timeout: 100 * time.Millisecond}
+		resp, err := sendRequest(cfg, cfg.URL, map[string]interface{}{"model": "m"})
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, strings.Count(string(body), "chunk"))
+	})
+
+	t.Run("idle stream is cut off", func(t *testing.T) {
+		server := httptest.NewServer(chunkHandler(0, 300*time.Millisecond))
+		defer server.Close()
+
+		cfg := config.ConfigData{URL: server.URL, APIKey: "sk-test", Stream: true, Timeout: 100 * time.Millisecond}
+		resp, err := sendRequest(cfg, cfg.URL, map[string]interface{}{"model": "m"})
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		_, err = io.ReadAll(resp.Body)
+		assert.ErrorContains(t, err, "stream idle for 100ms")
+	})
+}
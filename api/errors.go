@@ -0,0 +1,138 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"git.wisehodl.dev/jay/aicli/config"
+	"git.wisehodl.dev/jay/aicli/provider"
+)
+
+// APIError is a decoded non-200 response. Errors returned by SendChatRequest
+// and StreamChatRequest wrap it; inspect it with errors.As.
+type APIError = provider.APIError
+
+// contextLengthMarkers are lowercase fragments providers use when a request
+// exceeds the model's context window.
+var contextLengthMarkers = []string{
+	"context_length_exceeded",
+	"context length",
+	"context window",
+	"maximum context",
+	"prompt is too long",
+	"input is too long",
+	"too many tokens",
+	"exceeds the maximum number of tokens",
+}
+
+// parseError marks a response that arrived but could not be read.
+type parseError struct {
+	err error
+}
+
+func (e *parseError) Error() string { return e.err.Error() }
+
+func (e *parseError) Unwrap() error { return e.err }
+
+// classifyError sorts a failed attempt into an error class. Failures without
+// an HTTP response or parse error never reached the server and count as
+// network errors.
+func classifyError(err error) config.ErrorClass {
+	var parseErr *parseError
+	if errors.As(err, &parseErr) {
+		return config.ErrorParse
+	}
+
+	var httpErr *httpError
+	if !errors.As(err, &httpErr) {
+		return config.ErrorNetwork
+	}
+
+	switch code := httpErr.StatusCode; {
+	case code == http.StatusRequestEntityTooLarge || isContextLength(err):
+		return config.ErrorContextLength
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return config.ErrorAuth
+	case code == http.StatusTooManyRequests:
+		return config.ErrorRateLimit
+	case code >= 500:
+		return config.ErrorServer
+	default:
+		return config.ErrorRequest
+	}
+}
+
+// isContextLength reports whether an error message describes an oversized
+// prompt.
+func isContextLength(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, marker := range contextLengthMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldFallback reports whether a failure of the given class on model entry
+// from moves on to entry to. A nil FallbackOn falls back on every class. An
+// auth error always falls back to a different provider, which has its own
+// key.
+func shouldFallback(cfg config.ConfigData, class config.ErrorClass, from, to string) bool {
+	if cfg.FallbackOn == nil || slices.Contains(cfg.FallbackOn, class) {
+		return true
+	}
+	if class != config.ErrorAuth {
+		return false
+	}
+	_, _, fromProvider := cfg.ForModel(from)
+	_, _, toProvider := cfg.ForModel(to)
+	return fromProvider != toProvider
+}
+
+// ModelError records why one model in the fallback chain failed.
+type ModelError struct {
+	Model string
+	Class config.ErrorClass
+	Err   error
+}
+
+func (e ModelError) Error() string {
+	return fmt.Sprintf("%s (%s): %v", e.Model, e.Class, e.Err)
+}
+
+func (e ModelError) Unwrap() error { return e.Err }
+
+// ChainError is returned when no model in the fallback chain succeeded.
+// Skipped lists the models left untried because the last failure's class
+// does not trigger fallback.
+type ChainError struct {
+	Failures []ModelError
+	Skipped  []string
+}
+
+func (e *ChainError) Error() string {
+	causes := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		causes[i] = f.Error()
+	}
+	msg := strings.Join(causes, "; ")
+
+	if len(e.Skipped) > 0 {
+		last := e.Failures[len(e.Failures)-1]
+		return fmt.Sprintf("%s; not falling back on %s errors", msg, last.Class)
+	}
+	return "all models failed: " + msg
+}
+
+// Unwrap exposes each model's cause to errors.Is and errors.As.
+func (e *ChainError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
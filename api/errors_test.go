@@ -0,0 +1,111 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"git.wisehodl.dev/jay/aicli/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func statusErr(code int, body string) error {
+	return &httpError{StatusCode: code, err: fmt.Errorf("HTTP %d: %s", code, body)}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want config.ErrorClass
+	}{
+		{"401", statusErr(401, "invalid api key"), config.ErrorAuth},
+		{"403", statusErr(403, "forbidden"), config.ErrorAuth},
+		{"429", statusErr(429, "rate limited"), config.ErrorRateLimit},
+		{"500", statusErr(500, "internal"), config.ErrorServer},
+		{"529", statusErr(529, "overloaded"), config.ErrorServer},
+		{"413", statusErr(413, "payload too large"), config.ErrorContextLength},
+		{"openai context", statusErr(400, `{"error":{"code":"context_length_exceeded"}}`), config.ErrorContextLength},
+		{"anthropic context", statusErr(400, "prompt is too long: 210000 tokens"), config.ErrorContextLength},
+		{"400", statusErr(400, "bad request"), config.ErrorRequest},
+		{"404", statusErr(404, "model not found"), config.ErrorRequest},
+		{"parse", &parseError{err: errors.New("no choices")}, config.ErrorParse},
+		{"network", errors.New("execute request: connection refused"), config.ErrorNetwork},
+		{"wrapped", fmt.Errorf("attempt: %w", statusErr(401, "x")), config.ErrorAuth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyError(tt.err))
+		})
+	}
+}
+
+func TestChainError(t *testing.T) {
+	authErr := statusErr(401, "bad key")
+
+	t.Run("all failed", func(t *testing.T) {
+		err := &ChainError{Failures: []ModelError{
+			{Model: "a", Class: config.ErrorServer, Err: errors.New("HTTP 500: x")},
+			{Model: "b", Class: config.ErrorRateLimit, Err: errors.New("HTTP 429: y")},
+		}}
+		assert.Equal(t, "all models failed: a (server): HTTP 500: x; b (rate_limit): HTTP 429: y", err.Error())
+	})
+
+	t.Run("fallback skipped", func(t *testing.T) {
+		err := &ChainError{
+			Failures: []ModelError{{Model: "a", Class: config.ErrorAuth, Err: authErr}},
+			Skipped:  []string{"b"},
+		}
+		assert.Equal(t, "a (auth): HTTP 401: bad key; not falling back on auth errors", err.Error())
+	})
+
+	t.Run("unwraps causes", func(t *testing.T) {
+		var err error = &ChainError{Failures: []ModelError{{Model: "a", Class: config.ErrorAuth, Err: authErr}}}
+		var httpErr *httpError
+		assert.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, 401, httpErr.StatusCode)
+	})
+}
+
+func TestShouldFallback(t *testing.T) {
+	cfg := config.ConfigData{FallbackOn: []config.ErrorClass{config.ErrorServer}}
+	assert.True(t, shouldFallback(cfg, config.ErrorServer, "a", "b"))
+	assert.False(t, shouldFallback(cfg, config.ErrorAuth, "a", "b"))
+	assert.True(t, shouldFallback(config.ConfigData{}, config.ErrorAuth, "a", "b"))
+
+	cfg.Providers = map[string]config.ProviderConfig{"ollama": {}, "openai": {}}
+	assert.True(t, shouldFallback(cfg, config.ErrorAuth, "ollama:llama3", "openai:gpt-4o-mini"))
+	assert.True(t, shouldFallback(cfg, config.ErrorAuth, "openai:gpt-4o", "gpt-4o-mini"))
+	assert.False(t, shouldFallback(cfg, config.ErrorAuth, "openai:gpt-4o", "openai:gpt-4o-mini"))
+	assert.False(t, shouldFallback(cfg, config.ErrorRequest, "ollama:llama3", "openai:gpt-4o-mini"))
+}
+
+func TestSendChatRequestAPIError(t *testing.T) {
+	transport := &sequenceTransport{responses: []*http.Response{
+		withHeader(
+			makeResponse(401, `{"error":{"message":"Incorrect API key provided.","type":"invalid_request_error","code":"invalid_api_key"}}`),
+			"x-request-id", "req_abc",
+		),
+	}}
+	useTransport(t, transport)
+
+	cfg := config.ConfigData{
+		Protocol: config.ProtocolOpenAI,
+		URL:      "https://api.example.com",
+		APIKey:   "sk-bad",
+		Model:    "gpt-4",
+		Quiet:    true,
+	}
+
+	_, _, _, err := SendChatRequest(cfg, "test")
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 401, apiErr.StatusCode)
+	assert.Equal(t, "invalid_api_key", apiErr.Code)
+	assert.Equal(t, "Incorrect API key provided.", apiErr.Message)
+	assert.Equal(t, "req_abc", apiErr.RequestID)
+	assert.Equal(t, "all models failed: gpt-4 (auth): HTTP 401: Incorrect API key provided. (invalid_api_key) [request req_abc]", err.Error())
+}
@@ -2,46 +2,153 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sync/atomic"
 	"time"
 
 	"git.wisehodl.dev/jay/aicli/config"
 )
 
-var httpClient = &http.Client{Timeout: 5 * time.Minute}
-
 // executeHTTP sends the payload to the API endpoint and returns the response body.
-func executeHTTP(cfg config.ConfigData, payload map[string]interface{}) ([]byte, error) {
+func executeHTTP(cfg config.ConfigData, endpoint string, payload map[string]interface{}) ([]byte, error) {
+	resp, err := sendRequest(cfg, endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	return respBody, nil
+}
+
+// sendRequest posts the payload and returns the response with its body unread.
+// Non-200 responses are consumed, closed, and reported as errors. Transient
+// failures are retried according to cfg.Retries.
+func sendRequest(cfg config.ConfigData, endpoint string, payload map[string]interface{}) (*http.Response, error) {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("marshal payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", cfg.URL, bytes.NewReader(body))
+	target := endpoint
+	if _, httpURL, ok := splitUnixURL(endpoint); ok {
+		target = httpURL
+	}
+
+	req, err := http.NewRequest("POST", target, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", cfg.APIKey))
+	// A stream is cancelled when it goes quiet for longer than the timeout
+	cancel := context.CancelFunc(func() {})
+	if cfg.Stream && cfg.Timeout > 0 {
+		req, cancel = cancelable(req)
+	}
 
-	resp, err := httpClient.Do(req)
+	client, err := newHTTPClient(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
+		cancel()
+		return nil, fmt.Errorf("configure HTTP client: %w", err)
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	p := lookupProvider(cfg.Protocol)
+
+	req.Header.Set("Content-Type", "application/json")
+	authorize(req, p, cfg)
+
+	resp, err := withRetry(cfg, func() (*http.Response, error) {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+
+		resp, err := client.Do(req)
+		if err != nil {
+			// Report the configured endpoint, never a URL carrying the key
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				urlErr.URL = endpoint
+			}
+			return nil, fmt.Errorf("execute request: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+			respBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, fmt.Errorf("read response: %w", err)
+			}
+			return nil, &httpError{
+				StatusCode: resp.StatusCode,
+				Header:     resp.Header,
+				err:        p.DecodeError(resp.StatusCode, resp.Header, respBody),
+			}
+		}
+
+		return resp, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
+		cancel()
+		return nil, err
+	}
+	if cfg.Stream && cfg.Timeout > 0 {
+		resp.Body = newIdleTimeoutBody(resp.Body, cfg.Timeout, cancel)
 	}
+	return resp, nil
+}
+
+// cancelable returns req with a context that the returned func cancels.
+func cancelable(req *http.Request) (*http.Request, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(req.Context())
+	return req.WithContext(ctx), cancel
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
+// idleTimeoutBody cancels a streamed response that sends nothing for longer
+// than timeout. Each read that returns data restarts the clock.
+type idleTimeoutBody struct {
+	io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	expired atomic.Bool
+	cancel  context.CancelFunc
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *idleTimeoutBody {
+	b := &idleTimeoutBody{ReadCloser: body, timeout: timeout, cancel: cancel}
+	b.timer = time.AfterFunc(timeout, func() {
+		b.expired.Store(true)
+		cancel()
+	})
+	return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	if err != nil && err != io.EOF && b.expired.Load() {
+		err = fmt.Errorf("stream idle for %s (--timeout): %w", b.timeout, err)
 	}
+	return n, err
+}
 
-	return respBody, nil
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// endpointURL returns the URL a request for model is sent to.
+func endpointURL(cfg config.ConfigData, model string) string {
+	return lookupProvider(cfg.Protocol).Endpoint(newRequest(cfg, model, ""))
 }
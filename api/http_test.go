@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -24,6 +25,16 @@ func (m *mockRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	return m.response, m.err
 }
 
+// useTransport routes requests made during the test through rt.
+func useTransport(t *testing.T, rt http.RoundTripper) {
+	t.Helper()
+	old := newHTTPClient
+	newHTTPClient = func(config.ConfigData) (*http.Client, error) {
+		return &http.Client{Transport: rt}, nil
+	}
+	t.Cleanup(func() { newHTTPClient = old })
+}
+
 func makeResponse(statusCode int, body string) *http.Response {
 	return &http.Response{
 		StatusCode: statusCode,
@@ -119,14 +130,9 @@ func TestExecuteHTTP(t *testing.T) {
 				response: tt.mockResp,
 				err:      tt.mockErr,
 			}
-			oldClient := httpClient
-			httpClient = &http.Client{
-				Timeout:   5 * time.Minute,
-				Transport: transport,
-			}
-			defer func() { httpClient = oldClient }()
+			useTransport(t, transport)
 
-			got, err := executeHTTP(tt.cfg, tt.payload)
+			got, err := executeHTTP(tt.cfg, tt.cfg.URL, tt.payload)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -152,20 +158,149 @@ func TestExecuteHTTPHeaders(t *testing.T) {
 	transport := &mockRoundTripper{
 		response: makeResponse(200, `{"result":"ok"}`),
 	}
-	oldClient := httpClient
-	httpClient = &http.Client{
-		Timeout:   5 * time.Minute,
-		Transport: transport,
-	}
-	defer func() { httpClient = oldClient }()
+	useTransport(t, transport)
 
-	_, err := executeHTTP(cfg, payload)
+	_, err := executeHTTP(cfg, cfg.URL, payload)
 	assert.NoError(t, err)
 
 	assert.Equal(t, "application/json", transport.request.Header.Get("Content-Type"))
 	assert.Equal(t, "Bearer sk-test-key", transport.request.Header.Get("Authorization"))
 }
 
+func TestExecuteHTTPAnthropicHeaders(t *testing.T) {
+	cfg := config.ConfigData{
+		Protocol: config.ProtocolAnthropic,
+		URL:      "https://api.anthropic.com/v1/messages",
+		APIKey:   "sk-ant-test",
+	}
+	payload := map[string]interface{}{"model": "claude-sonnet-4-5"}
+
+	transport := &mockRoundTripper{
+		response: makeResponse(200, `{"content":[]}`),
+	}
+	useTransport(t, transport)
+
+	_, err := executeHTTP(cfg, cfg.URL, payload)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "sk-ant-test", transport.request.Header.Get("x-api-key"))
+	assert.Equal(t, "2023-06-01", transport.request.Header.Get("anthropic-version"))
+	assert.Empty(t, transport.request.Header.Get("Authorization"))
+}
+
+func TestEndpointURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   config.ConfigData
+		model string
+		want  string
+	}{
+		{
+			name:  "openai uses url as-is",
+			cfg:   config.ConfigData{Protocol: config.ProtocolOpenAI, URL: "https://api.example.com/v1/chat/completions"},
+			model: "gpt-4",
+			want:  "https://api.example.com/v1/chat/completions",
+		},
+		{
+			name:  "gemini appends model path",
+			cfg:   config.ConfigData{Protocol: config.ProtocolGemini, URL: "https://generativelanguage.googleapis.com/v1beta/"},
+			model: "gemini-2.5-flash",
+			want:  "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.5-flash:generateContent",
+		},
+		{
+			name:  "gemini streaming",
+			cfg:   config.ConfigData{Protocol: config.ProtocolGemini, URL: "https://generativelanguage.googleapis.com/v1beta", Stream: true},
+			model: "gemini-2.5-flash",
+			want:  "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.5-flash:streamGenerateContent?alt=sse",
+		},
+		{
+			name:  "azure deployment with default api version",
+			cfg:   config.ConfigData{Protocol: config.ProtocolAzure, URL: "https://corp.openai.azure.com/"},
+			model: "gpt-4o-prod",
+			want:  "https://corp.openai.azure.com/openai/deployments/gpt-4o-prod/chat/completions?api-version=2024-10-21",
+		},
+		{
+			name:  "azure configured api version",
+			cfg:   config.ConfigData{Protocol: config.ProtocolAzure, URL: "https://corp.openai.azure.com", APIVersion: "2025-01-01-preview"},
+			model: "gpt-4o-mini",
+			want:  "https://corp.openai.azure.com/openai/deployments/gpt-4o-mini/chat/completions?api-version=2025-01-01-preview",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, endpointURL(tt.cfg, tt.model))
+		})
+	}
+}
+
+func TestGeminiRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1beta/models/gemini-2.5-flash:generateContent", r.URL.Path)
+		assert.Equal(t, "goog-test-key", r.Header.Get("x-goog-api-key"))
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		body, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(body), `"systemInstruction"`)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"gemini says hi"}]}}]}`))
+	}))
+	defer server.Close()
+
+	cfg := config.ConfigData{
+		Protocol:     config.ProtocolGemini,
+		URL:          server.URL + "/v1beta",
+		APIKey:       "goog-test-key",
+		SystemPrompt: "be brief",
+	}
+
+	got, err := tryModel(cfg, "gemini-2.5-flash", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "gemini says hi", got)
+}
+
+func TestAzureFallbackDeployments(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		assert.Equal(t, "2024-06-01", r.URL.Query().Get("api-version"))
+		assert.Equal(t, "azure-key", r.Header.Get("api-key"))
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		assert.NotContains(t, payload, "model")
+
+		if strings.Contains(r.URL.Path, "/primary/") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":{"code":"ServiceUnavailable","message":"busy"}}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"azure says hi"}}]}`))
+	}))
+	defer server.Close()
+
+	cfg := config.ConfigData{
+		Protocol:       config.ProtocolAzure,
+		URL:            server.URL,
+		APIVersion:     "2024-06-01",
+		APIKey:         "azure-key",
+		Model:          "primary",
+		FallbackModels: []string{"secondary"},
+		Quiet:          true,
+	}
+
+	got, model, _, err := SendChatRequest(cfg, "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "azure says hi", got)
+	assert.Equal(t, "secondary", model)
+	assert.Equal(t, []string{
+		"/openai/deployments/primary/chat/completions",
+		"/openai/deployments/secondary/chat/completions",
+	}, paths)
+}
+
 func TestExecuteHTTPTimeout(t *testing.T) {
 	cfg := config.ConfigData{
 		URL:    "https://api.example.com/chat",
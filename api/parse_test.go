@@ -71,6 +71,105 @@ func TestParseResponse(t *testing.T) {
 			wantErr:     true,
 			errContains: "no response field in ollama response",
 		},
+		{
+			name:     "ollama chat success",
+			body:     `{"message":{"role":"assistant","content":"This is the chat response."},"done":true}`,
+			protocol: config.ProtocolOllamaChat,
+			want:     "This is the chat response.",
+		},
+		{
+			name:        "ollama chat no message field",
+			body:        `{"response":"generate shape"}`,
+			protocol:    config.ProtocolOllamaChat,
+			wantErr:     true,
+			errContains: "no message in ollama chat response",
+		},
+		{
+			name:        "ollama chat no content field",
+			body:        `{"message":{"role":"assistant"}}`,
+			protocol:    config.ProtocolOllamaChat,
+			wantErr:     true,
+			errContains: "no content in message",
+		},
+		{
+			name:     "anthropic success",
+			body:     `{"content":[{"type":"text","text":"Hello"},{"type":"text","text":", world"}]}`,
+			protocol: config.ProtocolAnthropic,
+			want:     "Hello, world",
+		},
+		{
+			name:     "anthropic skips non-text blocks",
+			body:     `{"content":[{"type":"thinking","thinking":"hmm"},{"type":"text","text":"answer"}]}`,
+			protocol: config.ProtocolAnthropic,
+			want:     "answer",
+		},
+		{
+			name:        "anthropic no content field",
+			body:        `{"type":"message"}`,
+			protocol:    config.ProtocolAnthropic,
+			wantErr:     true,
+			errContains: "no content in anthropic response",
+		},
+		{
+			name:        "anthropic no text blocks",
+			body:        `{"content":[{"type":"tool_use","name":"x"}]}`,
+			protocol:    config.ProtocolAnthropic,
+			wantErr:     true,
+			errContains: "no text blocks",
+		},
+		{
+			name:     "gemini success",
+			body:     `{"candidates":[{"content":{"parts":[{"text":"Hello"},{"text":", world"}]}}]}`,
+			protocol: config.ProtocolGemini,
+			want:     "Hello, world",
+		},
+		{
+			name:        "gemini no candidates",
+			body:        `{"candidates":[]}`,
+			protocol:    config.ProtocolGemini,
+			wantErr:     true,
+			errContains: "no candidates in gemini response",
+		},
+		{
+			name:        "gemini prompt blocked",
+			body:        `{"promptFeedback":{"blockReason":"SAFETY"}}`,
+			protocol:    config.ProtocolGemini,
+			wantErr:     true,
+			errContains: "prompt blocked: SAFETY",
+		},
+		{
+			name:        "gemini candidate without content",
+			body:        `{"candidates":[{"finishReason":"SAFETY"}]}`,
+			protocol:    config.ProtocolGemini,
+			wantErr:     true,
+			errContains: "no content in candidate",
+		},
+		{
+			name:     "responses skips reasoning items",
+			body:     `{"output":[{"type":"reasoning","summary":[{"type":"summary_text","text":"thinking"}]},{"type":"message","content":[{"type":"output_text","text":"answer"}]}]}`,
+			protocol: config.ProtocolResponses,
+			want:     "answer",
+		},
+		{
+			name:     "responses joins multiple output_text parts",
+			body:     `{"output":[{"type":"message","content":[{"type":"output_text","text":"Hello"},{"type":"refusal","refusal":"no"},{"type":"output_text","text":", world"}]}]}`,
+			protocol: config.ProtocolResponses,
+			want:     "Hello, world",
+		},
+		{
+			name:        "responses no output field",
+			body:        `{"status":"failed"}`,
+			protocol:    config.ProtocolResponses,
+			wantErr:     true,
+			errContains: "no output in responses response",
+		},
+		{
+			name:        "responses only reasoning",
+			body:        `{"output":[{"type":"reasoning","summary":[]}]}`,
+			protocol:    config.ProtocolResponses,
+			wantErr:     true,
+			errContains: "no output_text",
+		},
 		{
 			name:        "malformed json",
 			body:        `{invalid json`,
@@ -130,6 +229,30 @@ func TestParseResponseWithTestdata(t *testing.T) {
 			protocol: config.ProtocolOllama,
 			want:     "This is the Ollama response.",
 		},
+		{
+			name:     "ollama chat success from file",
+			file:     "testdata/ollama_chat_success.json",
+			protocol: config.ProtocolOllamaChat,
+			want:     "This is the Ollama chat response.",
+		},
+		{
+			name:     "anthropic success from file",
+			file:     "testdata/anthropic_success.json",
+			protocol: config.ProtocolAnthropic,
+			want:     "This is the Anthropic response.",
+		},
+		{
+			name:     "gemini success from file",
+			file:     "testdata/gemini_success.json",
+			protocol: config.ProtocolGemini,
+			want:     "This is the Gemini response.",
+		},
+		{
+			name:     "responses success from file",
+			file:     "testdata/responses_success.json",
+			protocol: config.ProtocolResponses,
+			want:     "This is the Responses API response.",
+		},
 		{
 			name:        "ollama no response from file",
 			file:        "testdata/ollama_no_response.json",
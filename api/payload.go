@@ -3,34 +3,23 @@ package api
 import "git.wisehodl.dev/jay/aicli/config"
 
 // buildPayload constructs the JSON payload for the API request based on protocol.
+// cfg.ExtraBody is merged over the provider's payload.
 func buildPayload(cfg config.ConfigData, model string, query string) map[string]interface{} {
-	if cfg.Protocol == config.ProtocolOllama {
-		payload := map[string]interface{}{
-			"model":  model,
-			"prompt": query,
-			"stream": false,
-		}
-		if cfg.SystemPrompt != "" {
-			payload["system"] = cfg.SystemPrompt
-		}
-		return payload
-	}
-
-	// OpenAI protocol
-	messages := []map[string]string{}
-	if cfg.SystemPrompt != "" {
-		messages = append(messages, map[string]string{
-			"role":    "system",
-			"content": cfg.SystemPrompt,
-		})
-	}
-	messages = append(messages, map[string]string{
-		"role":    "user",
-		"content": query,
-	})
+	payload := lookupProvider(cfg.Protocol).Payload(newRequest(cfg, model, query))
+	mergeBody(payload, cfg.ExtraBody)
+	return payload
+}
 
-	return map[string]interface{}{
-		"model":    model,
-		"messages": messages,
+// mergeBody copies src into dst, recursing where both hold an object.
+// Any other value in src replaces the one in dst.
+func mergeBody(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcOK := value.(map[string]interface{})
+		dstMap, dstOK := dst[key].(map[string]interface{})
+		if srcOK && dstOK {
+			mergeBody(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
 	}
 }
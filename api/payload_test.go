@@ -73,6 +73,168 @@ func TestBuildPayload(t *testing.T) {
 				"stream": false,
 			},
 		},
+		{
+			name: "ollama chat with system prompt",
+			cfg: config.ConfigData{
+				Protocol:     config.ProtocolOllamaChat,
+				SystemPrompt: "You are helpful",
+			},
+			model: "llama3",
+			query: "analyze this",
+			want: map[string]interface{}{
+				"model": "llama3",
+				"messages": []map[string]string{
+					{"role": "system", "content": "You are helpful"},
+					{"role": "user", "content": "analyze this"},
+				},
+				"stream": false,
+			},
+		},
+		{
+			name: "ollama chat streaming",
+			cfg: config.ConfigData{
+				Protocol: config.ProtocolOllamaChat,
+				Stream:   true,
+			},
+			model: "llama3",
+			query: "analyze this",
+			want: map[string]interface{}{
+				"model": "llama3",
+				"messages": []map[string]string{
+					{"role": "user", "content": "analyze this"},
+				},
+				"stream": true,
+			},
+		},
+		{
+			name: "anthropic without system prompt",
+			cfg: config.ConfigData{
+				Protocol: config.ProtocolAnthropic,
+			},
+			model: "claude-sonnet-4-5",
+			query: "analyze this",
+			want: map[string]interface{}{
+				"model":      "claude-sonnet-4-5",
+				"max_tokens": 4096,
+				"messages": []map[string]string{
+					{"role": "user", "content": "analyze this"},
+				},
+			},
+		},
+		{
+			name: "anthropic with system prompt and streaming",
+			cfg: config.ConfigData{
+				Protocol:     config.ProtocolAnthropic,
+				SystemPrompt: "You are helpful",
+				Stream:       true,
+			},
+			model: "claude-sonnet-4-5",
+			query: "analyze this",
+			want: map[string]interface{}{
+				"model":      "claude-sonnet-4-5",
+				"max_tokens": 4096,
+				"system":     "You are helpful",
+				"messages": []map[string]string{
+					{"role": "user", "content": "analyze this"},
+				},
+				"stream": true,
+			},
+		},
+		{
+			name: "gemini without system prompt",
+			cfg: config.ConfigData{
+				Protocol: config.ProtocolGemini,
+			},
+			model: "gemini-2.5-flash",
+			query: "analyze this",
+			want: map[string]interface{}{
+				"contents": []map[string]interface{}{
+					{
+						"role":  "user",
+						"parts": []map[string]string{{"text": "analyze this"}},
+					},
+				},
+			},
+		},
+		{
+			name: "gemini with system prompt",
+			cfg: config.ConfigData{
+				Protocol:     config.ProtocolGemini,
+				SystemPrompt: "You are helpful",
+				Stream:       true,
+			},
+			model: "gemini-2.5-flash",
+			query: "analyze this",
+			want: map[string]interface{}{
+				"contents": []map[string]interface{}{
+					{
+						"role":  "user",
+						"parts": []map[string]string{{"text": "analyze this"}},
+					},
+				},
+				"systemInstruction": map[string]interface{}{
+					"parts": []map[string]string{{"text": "You are helpful"}},
+				},
+			},
+		},
+		{
+			name: "responses without system prompt",
+			cfg: config.ConfigData{
+				Protocol: config.ProtocolResponses,
+			},
+			model: "o4-mini",
+			query: "analyze this",
+			want: map[string]interface{}{
+				"model": "o4-mini",
+				"input": "analyze this",
+			},
+		},
+		{
+			name: "responses with instructions and streaming",
+			cfg: config.ConfigData{
+				Protocol:     config.ProtocolResponses,
+				SystemPrompt: "You are helpful",
+				Stream:       true,
+			},
+			model: "o4-mini",
+			query: "analyze this",
+			want: map[string]interface{}{
+				"model":        "o4-mini",
+				"input":        "analyze this",
+				"instructions": "You are helpful",
+				"stream":       true,
+			},
+		},
+		{
+			name: "openai streaming",
+			cfg: config.ConfigData{
+				Protocol: config.ProtocolOpenAI,
+				Stream:   true,
+			},
+			model: "gpt-4",
+			query: "analyze this",
+			want: map[string]interface{}{
+				"model": "gpt-4",
+				"messages": []map[string]string{
+					{"role": "user", "content": "analyze this"},
+				},
+				"stream": true,
+			},
+		},
+		{
+			name: "ollama streaming",
+			cfg: config.ConfigData{
+				Protocol: config.ProtocolOllama,
+				Stream:   true,
+			},
+			model: "llama3",
+			query: "analyze this",
+			want: map[string]interface{}{
+				"model":  "llama3",
+				"prompt": "analyze this",
+				"stream": true,
+			},
+		},
 		{
 			name: "empty query",
 			cfg: config.ConfigData{
@@ -124,3 +286,163 @@ func TestBuildPayload(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildPayloadParams(t *testing.T) {
+	temperature := 0.2
+	topP := 0.9
+	maxTokens := 256
+	seed := int64(42)
+	params := config.ConfigData{
+		Temperature: &temperature,
+		TopP:        &topP,
+		MaxTokens:   &maxTokens,
+		Seed:        &seed,
+		Stop:        []string{"END"},
+	}
+
+	tests := []struct {
+		name     string
+		protocol config.APIProtocol
+		key      string
+		want     map[string]interface{}
+	}{
+		{
+			name:     "openai top level",
+			protocol: config.ProtocolOpenAI,
+			want: map[string]interface{}{
+				"temperature": 0.2,
+				"top_p":       0.9,
+				"max_tokens":  256,
+				"seed":        int64(42),
+				"stop":        []string{"END"},
+			},
+		},
+		{
+			name:     "ollama options",
+			protocol: config.ProtocolOllama,
+			key:      "options",
+			want: map[string]interface{}{
+				"temperature": 0.2,
+				"top_p":       0.9,
+				"num_predict": 256,
+				"seed":        int64(42),
+				"stop":        []string{"END"},
+			},
+		},
+		{
+			name:     "ollama chat options",
+			protocol: config.ProtocolOllamaChat,
+			key:      "options",
+			want: map[string]interface{}{
+				"temperature": 0.2,
+				"top_p":       0.9,
+				"num_predict": 256,
+				"seed":        int64(42),
+				"stop":        []string{"END"},
+			},
+		},
+		{
+			name:     "anthropic drops seed",
+			protocol: config.ProtocolAnthropic,
+			want: map[string]interface{}{
+				"temperature":    0.2,
+				"top_p":          0.9,
+				"max_tokens":     256,
+				"stop_sequences": []string{"END"},
+			},
+		},
+		{
+			name:     "gemini generation config",
+			protocol: config.ProtocolGemini,
+			key:      "generationConfig",
+			want: map[string]interface{}{
+				"temperature":     0.2,
+				"topP":            0.9,
+				"maxOutputTokens": 256,
+				"seed":            int64(42),
+				"stopSequences":   []string{"END"},
+			},
+		},
+		{
+			name:     "responses drops seed and stop",
+			protocol: config.ProtocolResponses,
+			want: map[string]interface{}{
+				"temperature":       0.2,
+				"top_p":             0.9,
+				"max_output_tokens": 256,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := params
+			cfg.Protocol = tt.protocol
+			payload := buildPayload(cfg, "model", "query")
+
+			got := payload
+			if tt.key != "" {
+				got = payload[tt.key].(map[string]interface{})
+			}
+			for k, v := range tt.want {
+				assert.Equal(t, v, got[k], k)
+			}
+			for _, k := range []string{"seed", "stop", "stop_sequences"} {
+				if _, ok := tt.want[k]; !ok {
+					assert.NotContains(t, got, k)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildPayloadOmitsUnsetParams(t *testing.T) {
+	for _, protocol := range []config.APIProtocol{config.ProtocolOllama, config.ProtocolGemini} {
+		payload := buildPayload(config.ConfigData{Protocol: protocol}, "model", "query")
+		assert.NotContains(t, payload, "options")
+		assert.NotContains(t, payload, "generationConfig")
+	}
+}
+
+func TestBuildPayloadExtraBody(t *testing.T) {
+	temperature := 0.5
+	cfg := config.ConfigData{
+		Protocol:    config.ProtocolOllamaChat,
+		Temperature: &temperature,
+		ExtraBody: map[string]interface{}{
+			"keep_alive": "10m",
+			"stream":     true,
+			"options":    map[string]interface{}{"num_ctx": 8192},
+		},
+	}
+
+	got := buildPayload(cfg, "llama3", "hi")
+
+	assert.Equal(t, "llama3", got["model"])
+	assert.Equal(t, "10m", got["keep_alive"])
+	assert.Equal(t, true, got["stream"])
+	assert.Equal(t, map[string]interface{}{
+		"temperature": 0.5,
+		"num_ctx":     8192,
+	}, got["options"])
+}
+
+func TestMergeBody(t *testing.T) {
+	dst := map[string]interface{}{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "hi"}},
+		"nested":   map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2}},
+	}
+	mergeBody(dst, map[string]interface{}{
+		"model":  "override",
+		"nested": map[string]interface{}{"b": map[string]interface{}{"d": 3}},
+		"new":    []interface{}{"x"},
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"model":    "override",
+		"messages": []map[string]string{{"role": "user", "content": "hi"}},
+		"nested":   map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2, "d": 3}},
+		"new":      []interface{}{"x"},
+	}, dst)
+}
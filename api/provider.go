@@ -0,0 +1,36 @@
+package api
+
+import (
+	"git.wisehodl.dev/jay/aicli/config"
+	"git.wisehodl.dev/jay/aicli/provider"
+)
+
+// lookupProvider returns the registered provider for protocol. Unset or
+// unknown protocols fall back to OpenAI; BuildConfig rejects unknown names
+// before any request is made.
+func lookupProvider(protocol config.APIProtocol) provider.Provider {
+	if p, ok := provider.Lookup(string(protocol)); ok {
+		return p
+	}
+	p, _ := provider.Lookup(string(config.ProtocolOpenAI))
+	return p
+}
+
+// newRequest describes a single model attempt for the provider.
+func newRequest(cfg config.ConfigData, model string, query string) provider.Request {
+	return provider.Request{
+		URL:        cfg.URL,
+		Model:      model,
+		System:     cfg.SystemPrompt,
+		Query:      query,
+		Stream:     cfg.Stream,
+		APIVersion: cfg.APIVersion,
+		Params: provider.Params{
+			Temperature: cfg.Temperature,
+			TopP:        cfg.TopP,
+			MaxTokens:   cfg.MaxTokens,
+			Seed:        cfg.Seed,
+			Stop:        cfg.Stop,
+		},
+	}
+}
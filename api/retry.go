@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"git.wisehodl.dev/jay/aicli/config"
+)
+
+// retryBaseDelay is the backoff delay before the first retry.
+const retryBaseDelay = 500 * time.Millisecond
+
+// sleep is replaced in tests to avoid real delays.
+var sleep = time.Sleep
+
+// httpError is a non-200 response, kept with its headers so the retry
+// logic can read rate limit hints.
+type httpError struct {
+	StatusCode int
+	Header     http.Header
+	err        error
+}
+
+func (e *httpError) Error() string { return e.err.Error() }
+
+func (e *httpError) Unwrap() error { return e.err }
+
+// retryable reports whether a failed attempt is worth repeating. HTTP errors
+// are retried for transient statuses, and network errors only when another
+// attempt could succeed: timeouts, refused or reset connections, and
+// connections closed early. TLS, DNS and other setup failures are not.
+func retryable(err error) bool {
+	var httpErr *httpError
+	if !errors.As(err, &httpErr) {
+		return transientNetworkError(err)
+	}
+
+	switch code := httpErr.StatusCode; {
+	case code == http.StatusRequestTimeout,
+		code == http.StatusTooEarly,
+		code == http.StatusTooManyRequests:
+		return true
+	case code == http.StatusNotImplemented,
+		code == http.StatusHTTPVersionNotSupported:
+		return false
+	default:
+		return code >= 500
+	}
+}
+
+// transientNetworkError reports whether err is a network failure that may
+// not recur.
+func transientNetworkError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// retryDelay returns how long to wait before retry number attempt (from 1).
+// A server-provided hint wins over backoff; both are capped at maxWait.
+func retryDelay(err error, attempt int, maxWait time.Duration) time.Duration {
+	var httpErr *httpError
+	if errors.As(err, &httpErr) {
+		if d, ok := serverDelay(httpErr.Header); ok {
+			return min(d, maxWait)
+		}
+	}
+
+	// Equal jitter: half the backoff is fixed, half is random.
+	backoff := retryBaseDelay << (attempt - 1)
+	if backoff <= 0 || backoff > maxWait {
+		backoff = maxWait
+	}
+	half := backoff / 2
+	if half <= 0 {
+		return backoff
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// serverDelay reads the wait requested by Retry-After, retry-after-ms, or
+// the longest of the x-ratelimit-reset-* headers.
+func serverDelay(h http.Header) (time.Duration, bool) {
+	if v := h.Get("retry-after-ms"); v != "" {
+		if ms, err := strconv.ParseFloat(v, 64); err == nil && ms >= 0 {
+			return time.Duration(ms * float64(time.Millisecond)), true
+		}
+	}
+
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(time.Until(t), 0), true
+		}
+	}
+
+	var longest time.Duration
+	found := false
+	for name, values := range h {
+		if !strings.HasPrefix(strings.ToLower(name), "x-ratelimit-reset") || len(values) == 0 {
+			continue
+		}
+		if d, ok := parseReset(values[0]); ok {
+			longest = max(longest, d)
+			found = true
+		}
+	}
+	return longest, found
+}
+
+// parseReset accepts a duration such as "6m0s" or "1.5s", or bare seconds.
+func parseReset(v string) (time.Duration, bool) {
+	if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+		return d, true
+	}
+	if secs, err := strconv.ParseFloat(v, 64); err == nil && secs >= 0 {
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	return 0, false
+}
+
+// withRetry runs attempt up to cfg.Retries+1 times, waiting between
+// retryable failures.
+func withRetry(cfg config.ConfigData, attempt func() (*http.Response, error)) (*http.Response, error) {
+	for n := 0; ; n++ {
+		resp, err := attempt()
+		if err == nil || n >= cfg.Retries || !retryable(err) {
+			return resp, err
+		}
+
+		delay := retryDelay(err, n+1, cfg.RetryMaxWait)
+		if !cfg.Quiet {
+			fmt.Fprintf(os.Stderr, "Request failed: %v; retrying in %.1fs (%d/%d)...\n",
+				err, delay.Seconds(), n+1, cfg.Retries)
+		}
+		sleep(delay)
+	}
+}
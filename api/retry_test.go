@@ -0,0 +1,275 @@
+package api
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"git.wisehodl.dev/jay/aicli/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func withHeader(resp *http.Response, key, value string) *http.Response {
+	resp.Header.Set(key, value)
+	return resp
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, true},
+		{"connection refused", &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}, true},
+		{"timeout", &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}}, true},
+		{"unexpected EOF", fmt.Errorf("execute request: %w", io.ErrUnexpectedEOF), true},
+		{"unknown host", &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "api.invalid", IsNotFound: true}}}, false},
+		{"untrusted certificate", &url.Error{Op: "Post", Err: x509.UnknownAuthorityError{}}, false},
+		{"other error", errors.New("unsupported protocol scheme"), false},
+		{"400", &httpError{StatusCode: 400}, false},
+		{"401", &httpError{StatusCode: 401}, false},
+		{"404", &httpError{StatusCode: 404}, false},
+		{"408", &httpError{StatusCode: 408}, true},
+		{"429", &httpError{StatusCode: 429}, true},
+		{"500", &httpError{StatusCode: 500}, true},
+		{"501", &httpError{StatusCode: 501}, false},
+		{"503", &httpError{StatusCode: 503}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, retryable(tt.err))
+		})
+	}
+}
+
+func TestServerDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		wantOK bool
+	}{
+		{
+			name:   "no hints",
+			header: http.Header{},
+			wantOK: false,
+		},
+		{
+			name:   "retry-after seconds",
+			header: http.Header{"Retry-After": {"7"}},
+			want:   7 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:   "retry-after-ms",
+			header: http.Header{"Retry-After-Ms": {"250"}, "Retry-After": {"7"}},
+			want:   250 * time.Millisecond,
+			wantOK: true,
+		},
+		{
+			name:   "retry-after date in the past",
+			header: http.Header{"Retry-After": {"Wed, 21 Oct 2015 07:28:00 GMT"}},
+			want:   0,
+			wantOK: true,
+		},
+		{
+			name: "longest ratelimit reset",
+			header: http.Header{
+				"X-Ratelimit-Reset-Requests": {"1.5s"},
+				"X-Ratelimit-Reset-Tokens":   {"6m0s"},
+			},
+			want:   6 * time.Minute,
+			wantOK: true,
+		},
+		{
+			name:   "ratelimit reset in seconds",
+			header: http.Header{"X-Ratelimit-Reset": {"3"}},
+			want:   3 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:   "unparseable values",
+			header: http.Header{"Retry-After": {"soon"}, "X-Ratelimit-Reset": {"later"}},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := serverDelay(tt.header)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	maxWait := 30 * time.Second
+
+	t.Run("backoff grows with jitter", func(t *testing.T) {
+		for attempt := 1; attempt <= 4; attempt++ {
+			backoff := retryBaseDelay << (attempt - 1)
+			got := retryDelay(errors.New("network"), attempt, maxWait)
+			assert.GreaterOrEqual(t, got, backoff/2)
+			assert.LessOrEqual(t, got, backoff)
+		}
+	})
+
+	t.Run("backoff capped at max wait", func(t *testing.T) {
+		got := retryDelay(errors.New("network"), 20, maxWait)
+		assert.LessOrEqual(t, got, maxWait)
+	})
+
+	t.Run("server hint capped at max wait", func(t *testing.T) {
+		err := &httpError{StatusCode: 429, Header: http.Header{"Retry-After": {"120"}}}
+		assert.Equal(t, maxWait, retryDelay(err, 1, maxWait))
+	})
+
+	t.Run("server hint used", func(t *testing.T) {
+		err := &httpError{StatusCode: 429, Header: http.Header{"Retry-After": {"2"}}}
+		assert.Equal(t, 2*time.Second, retryDelay(err, 1, maxWait))
+	})
+}
+
+func TestSendRequestRetries(t *testing.T) {
+	tests := []struct {
+		name        string
+		retries     int
+		responses   []*http.Response
+		wantErr     bool
+		errContains string
+		wantCalls   int
+		wantSleeps  []time.Duration
+	}{
+		{
+			name:    "retries disabled",
+			retries: 0,
+			responses: []*http.Response{
+				makeResponse(503, "unavailable"),
+			},
+			wantErr:     true,
+			errContains: "HTTP 503",
+			wantCalls:   1,
+		},
+		{
+			name:    "succeeds after rate limit",
+			retries: 2,
+			responses: []*http.Response{
+				withHeader(makeResponse(429, "slow down"), "Retry-After", "3"),
+				makeResponse(200, `{"choices":[{"message":{"content":"ok"}}]}`),
+			},
+			wantCalls:  2,
+			wantSleeps: []time.Duration{3 * time.Second},
+		},
+		{
+			name:    "gives up after retries",
+			retries: 2,
+			responses: []*http.Response{
+				withHeader(makeResponse(500, "a"), "Retry-After", "1"),
+				withHeader(makeResponse(502, "b"), "Retry-After", "1"),
+				withHeader(makeResponse(503, "c"), "Retry-After", "1"),
+			},
+			wantErr:     true,
+			errContains: "HTTP 503",
+			wantCalls:   3,
+			wantSleeps:  []time.Duration{time.Second, time.Second},
+		},
+		{
+			name:    "non-retryable status",
+			retries: 3,
+			responses: []*http.Response{
+				makeResponse(401, "unauthorized"),
+			},
+			wantErr:     true,
+			errContains: "HTTP 401",
+			wantCalls:   1,
+		},
+		{
+			name:    "network error retried",
+			retries: 1,
+			responses: []*http.Response{
+				nil,
+				makeResponse(200, `{}`),
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &sequenceTransport{responses: tt.responses}
+			useTransport(t, transport)
+
+			var sleeps []time.Duration
+			oldSleep := sleep
+			sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+			defer func() { sleep = oldSleep }()
+
+			cfg := config.ConfigData{
+				URL:          "https://api.example.com",
+				APIKey:       "sk-test",
+				Quiet:        true,
+				Retries:      tt.retries,
+				RetryMaxWait: 30 * time.Second,
+			}
+
+			resp, err := sendRequest(cfg, cfg.URL, map[string]interface{}{"model": "m"})
+			assert.Equal(t, tt.wantCalls, transport.index)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+				resp.Body.Close()
+			}
+
+			if tt.wantSleeps != nil {
+				assert.Equal(t, tt.wantSleeps, sleeps)
+			} else {
+				assert.Len(t, sleeps, tt.wantCalls-1)
+			}
+		})
+	}
+}
+
+func TestSendRequestTLSFailureNotRetried(t *testing.T) {
+	calls := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // handshake failures are expected
+	server.StartTLS()
+	defer server.Close()
+
+	var sleeps []time.Duration
+	oldSleep := sleep
+	sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	defer func() { sleep = oldSleep }()
+
+	// The test server's certificate is not in the system roots
+	cfg := config.ConfigData{
+		URL:          server.URL,
+		APIKey:       "sk-test",
+		Quiet:        true,
+		Retries:      2,
+		RetryMaxWait: 30 * time.Second,
+	}
+	_, err := sendRequest(cfg, cfg.URL, map[string]interface{}{"model": "m"})
+	assert.ErrorContains(t, err, "certificate")
+	assert.Empty(t, sleeps)
+	assert.Zero(t, calls)
+}
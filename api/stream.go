@@ -0,0 +1,22 @@
+package api
+
+import (
+	"io"
+
+	"git.wisehodl.dev/jay/aicli/config"
+)
+
+// StreamSink receives a response incrementally as it is generated.
+type StreamSink interface {
+	io.Writer
+
+	// Begin is called once the endpoint has accepted the request for model,
+	// before any content is written.
+	Begin(model string) error
+}
+
+// readStream copies streamed content from body to w as it arrives and
+// returns the complete response.
+func readStream(body io.Reader, protocol config.APIProtocol, w io.Writer) (string, error) {
+	return lookupProvider(protocol).ParseStream(body, w)
+}
@@ -0,0 +1,275 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"git.wisehodl.dev/jay/aicli/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSink struct {
+	bytes.Buffer
+	models []string
+}
+
+func (s *recordingSink) Begin(model string) error {
+	s.models = append(s.models, model)
+	return nil
+}
+
+func TestReadStream(t *testing.T) {
+	tests := []struct {
+		name        string
+		protocol    config.APIProtocol
+		body        string
+		want        string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:     "openai sse",
+			protocol: config.ProtocolOpenAI,
+			body: "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n" +
+				"data: [DONE]\n\n",
+			want: "Hello",
+		},
+		{
+			name:     "openai ignores comments and usage chunks",
+			protocol: config.ProtocolOpenAI,
+			body: ": keep-alive\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"ok\"}}]}\n\n" +
+				"data: {\"choices\":[],\"usage\":{\"total_tokens\":3}}\n\n" +
+				"data: [DONE]\n\n",
+			want: "ok",
+		},
+		{
+			name:     "openai ends without done marker",
+			protocol: config.ProtocolOpenAI,
+			body:     "data: {\"choices\":[{\"delta\":{\"content\":\"partial\"}}]}\n\n",
+			want:     "partial",
+		},
+		{
+			name:        "openai error event",
+			protocol:    config.ProtocolOpenAI,
+			body:        "data: {\"error\":{\"message\":\"overloaded\"}}\n\n",
+			wantErr:     true,
+			errContains: "overloaded",
+		},
+		{
+			name:        "openai malformed chunk",
+			protocol:    config.ProtocolOpenAI,
+			body:        "data: {invalid\n\n",
+			wantErr:     true,
+			errContains: "parse stream chunk",
+		},
+		{
+			name:     "anthropic events",
+			protocol: config.ProtocolAnthropic,
+			body: "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{}}\n\n" +
+				"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0}\n\n" +
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hel\"}}\n\n" +
+				"event: ping\ndata: {\"type\":\"ping\"}\n\n" +
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"lo\"}}\n\n" +
+				"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+			want: "Hello",
+		},
+		{
+			name:        "anthropic error event",
+			protocol:    config.ProtocolAnthropic,
+			body:        "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n",
+			wantErr:     true,
+			errContains: "Overloaded",
+		},
+		{
+			name:     "gemini sse",
+			protocol: config.ProtocolGemini,
+			body: "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"Hel\"}],\"role\":\"model\"}}]}\r\n\r\n" +
+				"data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"lo\"}],\"role\":\"model\"},\"finishReason\":\"STOP\"}]}\r\n\r\n",
+			want: "Hello",
+		},
+		{
+			name:     "responses events",
+			protocol: config.ProtocolResponses,
+			body: "event: response.created\ndata: {\"type\":\"response.created\",\"response\":{}}\n\n" +
+				"event: response.reasoning_summary_text.delta\ndata: {\"type\":\"response.reasoning_summary_text.delta\",\"delta\":\"thinking\"}\n\n" +
+				"event: response.output_text.delta\ndata: {\"type\":\"response.output_text.delta\",\"delta\":\"Hel\"}\n\n" +
+				"event: response.output_text.delta\ndata: {\"type\":\"response.output_text.delta\",\"delta\":\"lo\"}\n\n" +
+				"event: response.output_text.done\ndata: {\"type\":\"response.output_text.done\",\"text\":\"Hello\"}\n\n" +
+				"event: response.completed\ndata: {\"type\":\"response.completed\",\"response\":{}}\n\n",
+			want: "Hello",
+		},
+		{
+			name:        "responses failed event",
+			protocol:    config.ProtocolResponses,
+			body:        "event: response.failed\ndata: {\"type\":\"response.failed\",\"response\":{\"error\":{\"code\":\"server_error\",\"message\":\"failed\"}}}\n\n",
+			wantErr:     true,
+			errContains: "server_error",
+		},
+		{
+			name:     "ollama ndjson",
+			protocol: config.ProtocolOllama,
+			body: "{\"response\":\"Hel\",\"done\":false}\n" +
+				"{\"response\":\"lo\",\"done\":false}\n" +
+				"{\"response\":\"\",\"done\":true}\n",
+			want: "Hello",
+		},
+		{
+			name:     "ollama chat ndjson",
+			protocol: config.ProtocolOllamaChat,
+			body: "{\"message\":{\"role\":\"assistant\",\"content\":\"Hel\"},\"done\":false}\n" +
+				"{\"message\":{\"role\":\"assistant\",\"content\":\"lo\"},\"done\":false}\n" +
+				"{\"message\":{\"role\":\"assistant\",\"content\":\"\"},\"done\":true}\n",
+			want: "Hello",
+		},
+		{
+			name:        "ollama error chunk",
+			protocol:    config.ProtocolOllama,
+			body:        "{\"error\":\"model not found\"}\n",
+			wantErr:     true,
+			errContains: "model not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			got, err := readStream(strings.NewReader(tt.body), tt.protocol, &buf)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errContains != "" {
+					assert.Contains(t, err.Error(), tt.errContains)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestStreamChatRequest(t *testing.T) {
+	sse := func(parts ...string) string {
+		var b strings.Builder
+		for _, p := range parts {
+			fmt.Fprintf(&b, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", p)
+		}
+		b.WriteString("data: [DONE]\n\n")
+		return b.String()
+	}
+
+	tests := []struct {
+		name         string
+		cfg          config.ConfigData
+		mockResp     []*http.Response
+		wantResponse string
+		wantModel    string
+		wantBegins   []string
+		wantErr      bool
+		errContains  string
+	}{
+		{
+			name: "primary model streams",
+			cfg: config.ConfigData{
+				Protocol: config.ProtocolOpenAI,
+				URL:      "https://api.example.com",
+				APIKey:   "sk-test",
+				Model:    "gpt-4",
+				Stream:   true,
+				Quiet:    true,
+			},
+			mockResp: []*http.Response{
+				makeResponse(200, sse("Hello", ", ", "world")),
+			},
+			wantResponse: "Hello, world",
+			wantModel:    "gpt-4",
+			wantBegins:   []string{"gpt-4"},
+		},
+		{
+			name: "falls back before streaming begins",
+			cfg: config.ConfigData{
+				Protocol:       config.ProtocolOpenAI,
+				URL:            "https://api.example.com",
+				APIKey:         "sk-test",
+				Model:          "gpt-4",
+				FallbackModels: []string{"gpt-3.5"},
+				Stream:         true,
+				Quiet:          true,
+			},
+			mockResp: []*http.Response{
+				makeResponse(503, `{"error":"unavailable"}`),
+				makeResponse(200, sse("fallback")),
+			},
+			wantResponse: "fallback",
+			wantModel:    "gpt-3.5",
+			wantBegins:   []string{"gpt-3.5"},
+		},
+		{
+			name: "no fallback once streaming begins",
+			cfg: config.ConfigData{
+				Protocol:       config.ProtocolOpenAI,
+				URL:            "https://api.example.com",
+				APIKey:         "sk-test",
+				Model:          "gpt-4",
+				FallbackModels: []string{"gpt-3.5"},
+				Stream:         true,
+				Quiet:          true,
+			},
+			mockResp: []*http.Response{
+				makeResponse(200, "data: {\"choices\":[{\"delta\":{\"content\":\"par\"}}]}\n\ndata: {\"error\":{\"message\":\"boom\"}}\n\n"),
+			},
+			wantBegins:  []string{"gpt-4"},
+			wantErr:     true,
+			errContains: "interrupted",
+		},
+		{
+			name: "all models fail",
+			cfg: config.ConfigData{
+				Protocol:       config.ProtocolOllama,
+				URL:            "https://api.example.com",
+				APIKey:         "sk-test",
+				Model:          "llama3",
+				FallbackModels: []string{"mistral"},
+				Stream:         true,
+				Quiet:          true,
+			},
+			mockResp: []*http.Response{
+				makeResponse(500, `{"error":"error1"}`),
+				makeResponse(500, `{"error":"error2"}`),
+			},
+			wantErr:     true,
+			errContains: "all models failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &sequenceTransport{responses: tt.mockResp}
+
+			useTransport(t, transport)
+
+			sink := &recordingSink{}
+			response, model, _, err := StreamChatRequest(tt.cfg, "test", sink)
+
+			assert.Equal(t, tt.wantBegins, sink.models)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errContains != "" {
+					assert.Contains(t, err.Error(), tt.errContains)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantResponse, response)
+			assert.Equal(t, tt.wantResponse, sink.String())
+			assert.Equal(t, tt.wantModel, model)
+		})
+	}
+}
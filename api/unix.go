@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// unixScheme prefixes endpoints reached through a Unix domain socket. The
+// socket path is followed by a colon and the HTTP path, as in
+// unix:///run/ollama.sock:/api/chat.
+const unixScheme = "unix://"
+
+// splitUnixURL separates a unix:// endpoint into the socket path and the
+// HTTP URL to request over it. ok is false for any other endpoint.
+func splitUnixURL(endpoint string) (socket, httpURL string, ok bool) {
+	rest, ok := strings.CutPrefix(endpoint, unixScheme)
+	if !ok {
+		return "", "", false
+	}
+
+	socket, path, _ := strings.Cut(rest, ":")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return socket, "http://unix" + path, true
+}
+
+// dialUnix points transport at socket, ignoring the request's host. Proxies
+// do not apply to local sockets.
+func dialUnix(transport *http.Transport, dialer *net.Dialer, socket string) {
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", socket)
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"git.wisehodl.dev/jay/aicli/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitUnixURL(t *testing.T) {
+	tests := []struct {
+		endpoint   string
+		wantSocket string
+		wantURL    string
+		wantOK     bool
+	}{
+		{"unix:///run/ollama.sock:/api/chat", "/run/ollama.sock", "http://unix/api/chat", true},
+		{"unix:///run/gw.sock:/v1/chat/completions?api-version=1", "/run/gw.sock", "http://unix/v1/chat/completions?api-version=1", true},
+		{"unix:///run/ollama.sock", "/run/ollama.sock", "http://unix/", true},
+		{"unix:///run/ollama.sock:api/generate", "/run/ollama.sock", "http://unix/api/generate", true},
+		{"http://localhost:11434/api/chat", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			socket, httpURL, ok := splitUnixURL(tt.endpoint)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantSocket, socket)
+			assert.Equal(t, tt.wantURL, httpURL)
+		})
+	}
+}
+
+func TestUnixSocketRequest(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "ollama.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+
+	var gotPath string
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Write([]byte(`{"message":{"role":"assistant","content":"via socket"}}`))
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	cfg := config.ConfigData{
+		Protocol: config.ProtocolOllamaChat,
+		URL:      "unix://" + socket + ":/api/chat",
+		Auth:     config.AuthNone,
+		Proxy:    "http://unreachable.invalid:3128",
+	}
+
+	got, err := tryModel(cfg, "llama3", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "via socket", got)
+	assert.Equal(t, "/api/chat", gotPath)
+}
+
+func TestUnixSocketMissing(t *testing.T) {
+	cfg := config.ConfigData{
+		Protocol: config.ProtocolOllamaChat,
+		URL:      "unix://" + filepath.Join(t.TempDir(), "missing.sock") + ":/api/chat",
+		Auth:     config.AuthNone,
+	}
+
+	_, err := tryModel(cfg, "llama3", "hello")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.sock")
+	assert.Equal(t, config.ErrorNetwork, classifyError(err))
+}
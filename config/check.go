@@ -0,0 +1,309 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// warningOutput receives config problems when --strict is not set. Tests
+// point it elsewhere.
+var warningOutput io.Writer = os.Stderr
+
+// valueShape is the YAML node kinds a config key accepts.
+type valueShape int
+
+const (
+	shapeScalar valueShape = iota
+	shapeList              // a scalar or a list of scalars
+	shapeMap
+	shapeHeaders // a map, or a list of "Name: value" scalars
+)
+
+// fileKeyShapes maps every top-level config file key to its shape. Profiles
+// accept the same keys except profiles and default_profile.
+var fileKeyShapes = buildFileKeyShapes()
+
+// providerKeyShapes maps the keys of a providers entry to their shapes.
+var providerKeyShapes = map[string]valueShape{
+	"protocol":    shapeScalar,
+	"url":         shapeScalar,
+	"key":         shapeScalar,
+	"key_file":    shapeScalar,
+	"auth":        shapeScalar,
+	"api_version": shapeScalar,
+	"headers":     shapeMap,
+}
+
+func buildFileKeyShapes() map[string]valueShape {
+	shapes := map[string]valueShape{
+		"extra_body":      shapeMap,
+		"providers":       shapeMap,
+		"profiles":        shapeMap,
+		"default_profile": shapeScalar,
+		"headers":         shapeHeaders,
+	}
+	for _, o := range options {
+		if o.noFile || o.key == "headers" {
+			continue
+		}
+		if o.kind == kindList {
+			shapes[o.key] = shapeList
+		} else {
+			shapes[o.key] = shapeScalar
+		}
+	}
+	return shapes
+}
+
+// checkConfigFile reports unknown keys and values of the wrong shape in one
+// config file, as "path:line: problem".
+func checkConfigFile(path string, doc *yaml.Node) []string {
+	c := fileChecker{path: path}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		root := doc.Content[0]
+		if root.Kind != yaml.MappingNode {
+			c.report(root, "config must be a map of keys to values")
+			return c.problems
+		}
+		c.checkKeys(root, "", fileKeyShapes, c.checkTopLevel)
+	}
+	return c.problems
+}
+
+type fileChecker struct {
+	path     string
+	problems []string
+}
+
+func (c *fileChecker) report(node *yaml.Node, format string, args ...interface{}) {
+	c.problems = append(c.problems, fmt.Sprintf("%s:%d: ", c.path, node.Line)+fmt.Sprintf(format, args...))
+}
+
+// checkKeys checks each key of a mapping against shapes, then passes the
+// known ones to nested for any further checks. Scalars an option cannot
+// parse are removed from the mapping, so the setting falls back to lower
+// layers instead of failing the run.
+func (c *fileChecker) checkKeys(m *yaml.Node, prefix string, shapes map[string]valueShape, nested func(key string, value *yaml.Node)) {
+	known := make([]string, 0, len(shapes))
+	for key := range shapes {
+		known = append(known, key)
+	}
+
+	kept := m.Content[:0]
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		key, value := m.Content[i], resolveAlias(m.Content[i+1])
+		shape, ok := shapes[key.Value]
+		switch {
+		case !ok:
+			if suggestion := closestMatch(key.Value, known); suggestion != "" {
+				c.report(key, "unknown key %q (did you mean %q?)", prefix+key.Value, prefix+suggestion)
+			} else {
+				c.report(key, "unknown key %q", prefix+key.Value)
+			}
+		case !shapeMatches(shape, value):
+			c.report(value, "%s must be %s, got %s", prefix+key.Value, shapeName(shape), nodeName(value))
+		case shape == shapeScalar && !c.checkScalar(prefix, key.Value, value):
+			continue
+		case nested != nil:
+			nested(key.Value, value)
+		}
+		kept = append(kept, m.Content[i], m.Content[i+1])
+	}
+	m.Content = kept
+}
+
+// checkScalar parses a scalar the way the option named key would,
+// reporting whether the value is usable.
+func (c *fileChecker) checkScalar(prefix, key string, value *yaml.Node) bool {
+	o, ok := findOption(key)
+	if !ok || o.apply == nil || value.Kind != yaml.ScalarNode || value.Tag == "!!null" {
+		return true
+	}
+	if err := o.apply(&ConfigData{}, value.Value, prefix+key); err != nil {
+		c.report(value, "%v", err)
+		return false
+	}
+	return true
+}
+
+// checkTopLevel descends into the profiles and providers maps.
+func (c *fileChecker) checkTopLevel(key string, value *yaml.Node) {
+	switch key {
+	case "profiles":
+		profileShapes := make(map[string]valueShape, len(fileKeyShapes))
+		for k, shape := range fileKeyShapes {
+			if k != "profiles" && k != "default_profile" {
+				profileShapes[k] = shape
+			}
+		}
+		c.checkEntries(value, "profiles", profileShapes)
+	case "providers":
+		c.checkEntries(value, "providers", providerKeyShapes)
+	}
+}
+
+// checkEntries checks each named entry of a profiles or providers map.
+func (c *fileChecker) checkEntries(m *yaml.Node, section string, shapes map[string]valueShape) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		name, entry := m.Content[i].Value, resolveAlias(m.Content[i+1])
+		if entry.Kind != yaml.MappingNode {
+			c.report(entry, "%s.%s must be a map, got %s", section, name, nodeName(entry))
+			continue
+		}
+		c.checkKeys(entry, section+"."+name+".", shapes, nil)
+	}
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// shapeMatches reports whether node has the given shape. An empty value is
+// accepted as unset.
+func shapeMatches(shape valueShape, node *yaml.Node) bool {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return true
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return shape == shapeScalar || shape == shapeList || shape == shapeHeaders
+	case yaml.MappingNode:
+		return shape == shapeMap || shape == shapeHeaders
+	case yaml.SequenceNode:
+		if shape != shapeList && shape != shapeHeaders {
+			return false
+		}
+		for _, item := range node.Content {
+			if resolveAlias(item).Kind != yaml.ScalarNode {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func shapeName(shape valueShape) string {
+	switch shape {
+	case shapeList:
+		return "a value or a list of values"
+	case shapeMap:
+		return "a map"
+	case shapeHeaders:
+		return "a map or a list of \"Name: value\" entries"
+	}
+	return "a single value"
+}
+
+func nodeName(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.MappingNode:
+		return "a map"
+	}
+	return fmt.Sprintf("%q", node.Value)
+}
+
+// closestMatch returns the candidate nearest to s by edit distance, if any
+// is close enough to be a likely typo.
+func closestMatch(s string, candidates []string) string {
+	sort.Strings(candidates)
+	best, bestDistance := "", 3
+	for _, c := range candidates {
+		if d := editDistance(s, c); d < bestDistance && d < len(c) {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+// editDistance counts the insertions, deletions, substitutions and
+// transpositions of adjacent characters needed to turn a into b.
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// checkURLs reports endpoint settings that are not http, https or unix
+// URLs, naming where each was set.
+func checkURLs(cfg ConfigData, at origins) []string {
+	var problems []string
+	check := func(key, name, value string) {
+		if value != "" && !validEndpoint(value) {
+			problems = append(problems, fmt.Sprintf("%s: invalid %s: must be an http, https or unix:// URL, got: %s", at.lookup(key), name, value))
+		}
+	}
+
+	// Azure copies the endpoint into url, so it is checked once
+	if cfg.Endpoint != cfg.URL {
+		check("endpoint", "endpoint", cfg.Endpoint)
+	}
+	check("url", "url", cfg.URL)
+	names := make([]string, 0, len(cfg.Providers))
+	for name := range cfg.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		check("providers."+name, "providers."+name+".url", cfg.Providers[name].URL)
+	}
+	return problems
+}
+
+func validEndpoint(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return u.Host != ""
+	case "unix":
+		return u.Path != ""
+	}
+	return false
+}
+
+// reportProblems writes config problems as warnings, or returns them as an
+// error under --strict.
+func reportProblems(cfg ConfigData, problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	if cfg.Strict {
+		return fmt.Errorf("config problems (--strict):\n  %s", strings.Join(problems, "\n  "))
+	}
+	for _, p := range problems {
+		fmt.Fprintf(warningOutput, "warning: %s\n", p)
+	}
+	return nil
+}
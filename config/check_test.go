@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+func TestCheckConfigFile(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []string
+	}{
+		{
+			name: "valid keys and shapes",
+			yaml: `model: gpt-4o
+fallback: [gpt-4o-mini, gpt-4.1-mini]
+stop: END
+headers:
+  X-Team: search
+extra_body:
+  keep_alive: 10m
+providers:
+  local:
+    url: http://localhost:11434/api/chat
+    headers:
+      X-Trace: "1"
+profiles:
+  fast:
+    model: gpt-4o-mini
+default_profile: fast
+temperature:
+`,
+		},
+		{
+			name: "unknown keys with suggestions",
+			yaml: `modle: gpt-4o
+temprature: 0.2
+colour: blue
+`,
+			want: []string{
+				`cfg.yaml:1: unknown key "modle" (did you mean "model"?)`,
+				`cfg.yaml:2: unknown key "temprature" (did you mean "temperature"?)`,
+				`cfg.yaml:3: unknown key "colour"`,
+			},
+		},
+		{
+			name: "wrong shapes",
+			yaml: `model: [a, b]
+fallback:
+  first: a
+extra_body: fast
+headers:
+  - [X-Team, search]
+`,
+			want: []string{
+				`cfg.yaml:1: model must be a single value, got a list`,
+				`cfg.yaml:3: fallback must be a value or a list of values, got a map`,
+				`cfg.yaml:4: extra_body must be a map, got "fast"`,
+				`cfg.yaml:6: headers must be a map or a list of "Name: value" entries, got a list`,
+			},
+		},
+		{
+			name: "profiles and providers",
+			yaml: `profiles:
+  fast:
+    modle: gpt-4o-mini
+    default_profile: slow
+  broken: yes
+providers:
+  local:
+    ulr: http://localhost:11434/api/chat
+    headers: X-Trace
+`,
+			want: []string{
+				`cfg.yaml:3: unknown key "profiles.fast.modle" (did you mean "profiles.fast.model"?)`,
+				`cfg.yaml:4: unknown key "profiles.fast.default_profile"`,
+				`cfg.yaml:5: profiles.broken must be a map, got "yes"`,
+				`cfg.yaml:8: unknown key "providers.local.ulr" (did you mean "providers.local.url"?)`,
+				`cfg.yaml:9: providers.local.headers must be a map, got "X-Trace"`,
+			},
+		},
+		{
+			name: "mistyped values",
+			yaml: `temperature: hot
+stream: 5
+timeout: soon
+profiles:
+  fast:
+    max_tokens: many
+providers:
+  local:
+    auth: cookie
+`,
+			want: []string{
+				`cfg.yaml:1: invalid temperature: must be a number, got: hot`,
+				`cfg.yaml:2: invalid stream: must be true or false, got: 5`,
+				`cfg.yaml:3: invalid timeout: must be a duration such as 5m, got: soon`,
+				`cfg.yaml:6: invalid profiles.fast.max_tokens: must be an integer, got: many`,
+				`cfg.yaml:9: invalid providers.local.auth: must be bearer, header:NAME, query:NAME or none, got: cookie`,
+			},
+		},
+		{
+			name: "not a map",
+			yaml: "- model\n",
+			want: []string{`cfg.yaml:1: config must be a map of keys to values`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc yaml.Node
+			assert.NoError(t, yaml.Unmarshal([]byte(tt.yaml), &doc))
+			assert.Equal(t, tt.want, checkConfigFile("cfg.yaml", &doc))
+		})
+	}
+}
+
+func TestCheckConfigFileDropsMistypedValues(t *testing.T) {
+	var doc yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte("temperature: hot\nmodel: gpt-4o\nprofiles:\n  fast:\n    stream: 5\n"), &doc))
+	checkConfigFile("cfg.yaml", &doc)
+
+	var values map[string]interface{}
+	assert.NoError(t, doc.Decode(&values))
+	assert.Equal(t, map[string]interface{}{
+		"model":    "gpt-4o",
+		"profiles": map[string]interface{}{"fast": map[string]interface{}{}},
+	}, values)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("model", "model"))
+	assert.Equal(t, 1, editDistance("modle", "model"))
+	assert.Equal(t, 1, editDistance("mode", "model"))
+	assert.Equal(t, 2, editDistance("tmeprature", "temperature"))
+	assert.Equal(t, 5, editDistance("", "model"))
+}
+
+func TestCheckURLs(t *testing.T) {
+	cfg := ConfigData{
+		URL: "htp//example.com",
+		Providers: map[string]ProviderConfig{
+			"local":  {URL: "http://localhost:11434/api/chat"},
+			"socket": {URL: "unix:///run/ollama.sock:/api/chat"},
+			"remote": {URL: "api.example.com/v1"},
+		},
+	}
+	at := origins{
+		"url":              {kind: originFlag, name: "--url"},
+		"providers.remote": {kind: originFile, path: "cfg.yaml", line: 7},
+	}
+
+	assert.Equal(t, []string{
+		"flag --url: invalid url: must be an http, https or unix:// URL, got: htp//example.com",
+		"cfg.yaml:7: invalid providers.remote.url: must be an http, https or unix:// URL, got: api.example.com/v1",
+	}, checkURLs(cfg, at))
+}
+
+// captureWarnings collects warnings for the rest of the test.
+func captureWarnings(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var out bytes.Buffer
+	old := warningOutput
+	warningOutput = &out
+	t.Cleanup(func() { warningOutput = old })
+	return &out
+}
+
+func TestReportProblems(t *testing.T) {
+	out := captureWarnings(t)
+
+	problems := []string{`cfg.yaml:1: unknown key "modle" (did you mean "model"?)`}
+
+	assert.NoError(t, reportProblems(ConfigData{}, nil))
+	assert.NoError(t, reportProblems(ConfigData{}, problems))
+	assert.Equal(t, "warning: cfg.yaml:1: unknown key \"modle\" (did you mean \"model\"?)\n", out.String())
+
+	err := reportProblems(ConfigData{Strict: true}, problems)
+	assert.EqualError(t, err, "config problems (--strict):\n  cfg.yaml:1: unknown key \"modle\" (did you mean \"model\"?)")
+}
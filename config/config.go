@@ -1,64 +1,57 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"strings"
 )
 
-const UsageText = `Usage: aicli [OPTION]...
+// UsageText is the full --help output. The option and environment variable
+// lists are generated from optionSections.
+var UsageText = strings.NewReplacer(
+	"{protocols}", protocolList(),
+	"{classes}", errorClassList(),
+).Replace(usageHeader + optionUsage() + envUsage() + "\n" + usageFooter)
+
+const usageHeader = `Usage: aicli [OPTION]...
+       aicli config show|path [OPTION]...
 Send prompts and files to LLM chat endpoints.
 
 Global:
   --version                display version and exit
 
-Input:
-  -f, --file PATH          input file (repeatable)
-  -F, --stdin-file         treat stdin as file content
-  -p, --prompt TEXT        prompt text (repeatable)
-  -pf, --prompt-file PATH  read prompt from file
-
-System:
-  -s, --system TEXT        system prompt text
-  -sf, --system-file PATH  read system prompt from file
-                           (error if both -s and -sf provided)
-
-API:
-  -l, --protocol PROTO     openai or ollama (default: openai)
-  -u, --url URL            endpoint (default: https://api.ppq.ai/chat/completions)
-  -k, --key KEY            API key
-  -kf, --key-file PATH     read API key from file
-
-Models:
-  -m, --model NAME         primary model (default: gpt-4o-mini)
-  -b, --fallback NAMES     comma-separated fallback list (default: gpt-4.1-mini)
-
-Output:
-  -o, --output PATH        write to file (mode 0644) instead of stdout
-  -q, --quiet              suppress progress messages
-  -v, --verbose            log debug information to stderr
-
-Config:
-  -c, --config PATH        YAML config file
-
-Environment Variables:
-  AICLI_API_KEY            API key
-  AICLI_API_KEY_FILE       path to API key file
-  AICLI_PROTOCOL           API protocol
-  AICLI_URL                endpoint URL
-  AICLI_MODEL              primary model name
-  AICLI_FALLBACK           comma-separated fallback models
-  AICLI_SYSTEM             system prompt text
-  AICLI_SYSTEM_FILE        path to system prompt file
-  AICLI_CONFIG_FILE        path to config file
-  AICLI_PROMPT_FILE        path to prompt file
-  AICLI_DEFAULT_PROMPT     override default prompt
-
-Precedence Rules:
-  API key:      --key > --key-file > AICLI_API_KEY > AICLI_API_KEY_FILE > config key_file
-  System:       --system > --system-file > AICLI_SYSTEM > AICLI_SYSTEM_FILE > config system_file
-  Config file:  --config > AICLI_CONFIG_FILE
+Commands:
+  config show              print each setting with its source; the key is
+                           redacted
+  config path              list the config files searched
+
+`
+
+const usageFooter = `Precedence Rules:
+  API key:      --key > --key-file > --key-cmd > AICLI_API_KEY > AICLI_API_KEY_FILE >
+                AICLI_API_KEY_CMD > config key > config key_file > config key_cmd;
+                a key command runs only when it wins
+  System:       --system > --system-file > AICLI_SYSTEM > AICLI_SYSTEM_FILE > config system > config system_file
+  Config file:  --config > AICLI_CONFIG_FILE; otherwise these are merged,
+                later ones winning: /etc/aicli/config.yaml,
+                $XDG_CONFIG_HOME/aicli/config.yaml (default ~/.config),
+                the nearest .aicli.yaml at or above the working directory
+  Profile:      --profile > AICLI_PROFILE > config default_profile
   All others:   flags > environment > config file > defaults
 
+Config File Keys:
+  Long option names with "_" for "-", such as max_tokens and key_file.
+  -f, -p and --header are files, prompts and headers; lists may be YAML
+  lists and headers a map. --param is an extra_body map. The file may also
+  define providers, profiles and default_profile. Unknown keys and
+  mistyped values are warnings, or errors with --strict.
+
+Paths:
+  File paths from any source may use ~, $VAR and ${VAR}. Relative paths
+  in a config file are relative to that file's directory.
+
 Stdin Behavior:
   No flags:     stdin becomes the prompt
   With -p/-pf:  stdin appends after explicit prompts
@@ -79,45 +72,111 @@ func printUsage() {
 // BuildConfig resolves configuration from all sources with precedence:
 // flags > env > file > defaults
 func BuildConfig(args []string) (ConfigData, error) {
-	flags, err := parseFlags(args)
+	r, err := resolveConfig(args)
 	if err != nil {
-		return ConfigData{}, fmt.Errorf("parse flags: %w", err)
+		return ConfigData{}, err
+	}
+
+	if err := reportProblems(r.cfg, r.problems); err != nil {
+		return ConfigData{}, err
+	}
+	if err := validateConfig(r.cfg); err != nil {
+		return ConfigData{}, err
 	}
 
-	// Validate protocol strings before merge
-	if flags.protocol != "" && flags.protocol != "openai" && flags.protocol != "ollama" {
-		return ConfigData{}, fmt.Errorf("invalid protocol: must be openai or ollama, got: %s", flags.protocol)
+	return r.cfg, nil
+}
+
+// resolution is an unvalidated configuration, where it came from, and any
+// problems found in the config files and URLs.
+type resolution struct {
+	cfg      ConfigData
+	origins  origins
+	problems []string
+}
+
+func resolveConfig(args []string) (resolution, error) {
+	flags, err := parseFlags(args)
+	if err != nil {
+		return resolution{}, fmt.Errorf("parse flags: %w", err)
 	}
 
-	configPath := flags.config
-	if configPath == "" {
-		configPath = os.Getenv("AICLI_CONFIG_FILE")
+	env, dotenv, err := loadEnvLayer(flags)
+	if err != nil {
+		return resolution{}, err
 	}
 
-	env := loadEnvironment()
+	file, err := loadConfigFiles(configFiles(flags, env), selectedProfile(flags, env))
+	if err != nil {
+		return resolution{}, fmt.Errorf("load config file: %w", err)
+	}
 
-	// Validate env protocol
-	if env.protocol != "" && env.protocol != "openai" && env.protocol != "ollama" {
-		return ConfigData{}, fmt.Errorf("invalid protocol: must be openai or ollama, got: %s", env.protocol)
+	// ./.env enabled by the config file is read too late to choose the
+	// config file or profile
+	if dotenv == nil && dotenvEnabled(flags, env, file) {
+		dotenv, err = readEnvFile(defaultEnvPath())
+		if err == nil {
+			env = loadEnvironment(dotenv)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return resolution{}, err
+		}
 	}
 
-	file, err := loadConfigFile(configPath)
+	cfg, at, err := mergeSources(flags, env, file)
 	if err != nil {
-		return ConfigData{}, fmt.Errorf("load config file: %w", err)
+		return resolution{}, err
+	}
+	if dotenv != nil && cfg.EnvFile == "" {
+		cfg.EnvFile = dotenv.path
+		at["env_file"] = at.lookup("dotenv")
+	}
+
+	problems := append(file.problems, checkURLs(cfg, at)...)
+	return resolution{cfg, at, problems}, nil
+}
+
+// loadEnvLayer reads the environment, falling back to the env file named by
+// --env-file or AICLI_ENV_FILE, which it also returns.
+func loadEnvLayer(flags sourceValues) (sourceValues, *envFile, error) {
+	env := loadEnvironment(nil)
+	path := flags.envFile
+	if path == "" {
+		path = env.envFile
+	}
+	if path == "" {
+		return env, nil, nil
 	}
 
-	// Validate file protocol
-	if file.protocol != "" && file.protocol != "openai" && file.protocol != "ollama" {
-		return ConfigData{}, fmt.Errorf("invalid protocol: must be openai or ollama, got: %s", file.protocol)
+	dotenv, err := readEnvFile(path)
+	if err != nil {
+		return sourceValues{}, nil, err
 	}
+	return loadEnvironment(dotenv), dotenv, nil
+}
 
-	cfg := mergeSources(flags, env, file)
+// configFiles returns the config files to load. An explicit config file
+// replaces discovery.
+func configFiles(flags, env sourceValues) []string {
+	if path := explicitConfig(flags, env); path != "" {
+		return []string{path}
+	}
+	return discoverConfigFiles()
+}
 
-	if err := validateConfig(cfg); err != nil {
-		return ConfigData{}, err
+// explicitConfig returns the config file named by --config or
+// AICLI_CONFIG_FILE, if any.
+func explicitConfig(flags, env sourceValues) string {
+	if flags.config != "" {
+		return flags.config
 	}
+	return env.config
+}
 
-	return cfg, nil
+func selectedProfile(flags, env sourceValues) string {
+	if flags.profile != "" {
+		return flags.profile
+	}
+	return env.profile
 }
 
 // IsVersionRequest checks if --version flag was passed
@@ -1,8 +1,10 @@
 package config
 
 import (
-	"github.com/stretchr/testify/assert"
+	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestBuildConfig(t *testing.T) {
@@ -11,6 +13,8 @@ func TestBuildConfig(t *testing.T) {
 		args    []string
 		env     map[string]string
 		wantErr bool
+		project string
+		dotenv  string
 		check   func(*testing.T, ConfigData)
 	}{
 		{
@@ -29,6 +33,61 @@ func TestBuildConfig(t *testing.T) {
 				assert.Equal(t, "gpt-4", cfg.Model)
 			},
 		},
+		{
+			name: "profile from flag",
+			args: []string{"-c", "testdata/profiles.yaml", "--profile", "creative", "-k", "sk-test"},
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, "creative", cfg.Profile)
+				assert.Equal(t, "gpt-4o", cfg.Model)
+				assert.Equal(t, "You are a creative writer", cfg.SystemPrompt)
+			},
+		},
+		{
+			name: "profile from env",
+			args: []string{"-c", "testdata/profiles.yaml"},
+			env:  map[string]string{"AICLI_PROFILE": "local"},
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, ProtocolOllamaChat, cfg.Protocol)
+				assert.Equal(t, "llama3", cfg.Model)
+			},
+		},
+		{
+			name: "flags override profile",
+			args: []string{"-c", "testdata/profiles.yaml", "--profile", "creative", "-k", "sk-test", "-m", "gpt-4.1"},
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, "gpt-4.1", cfg.Model)
+			},
+		},
+		{
+			name:    "unknown profile",
+			args:    []string{"-c", "testdata/profiles.yaml", "--profile", "missing", "-k", "sk-test"},
+			wantErr: true,
+		},
+		{
+			name:    "project config discovered",
+			args:    []string{"-k", "sk-test"},
+			project: "model: project-model\n",
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, "project-model", cfg.Model)
+			},
+		},
+		{
+			name:    "explicit config replaces discovery",
+			args:    []string{"-k", "sk-test", "-c", "testdata/partial.yaml"},
+			project: "model: project-model\nurl: https://project.example.com\n",
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, "gpt-4", cfg.Model)
+				assert.Equal(t, "https://api.ppq.ai/chat/completions", cfg.URL)
+			},
+		},
+		{
+			name:    "flags override discovered config",
+			args:    []string{"-k", "sk-test", "-m", "flag-model"},
+			project: "model: project-model\n",
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, "flag-model", cfg.Model)
+			},
+		},
 		{
 			name:    "missing api key",
 			args:    []string{},
@@ -44,19 +103,176 @@ func TestBuildConfig(t *testing.T) {
 			args:    []string{"-k", "sk-test", "-l", "invalid"},
 			wantErr: true,
 		},
+		{
+			name:    "invalid retries in flags",
+			args:    []string{"-k", "sk-test", "--retries", "x"},
+			wantErr: true,
+		},
+		{
+			name:    "temperature out of range",
+			args:    []string{"-k", "sk-test", "--temperature", "3"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid param",
+			args:    []string{"-k", "sk-test", "--param", "novalue"},
+			wantErr: true,
+		},
+		{
+			name:    "fallback list in config",
+			args:    []string{"-k", "sk-test"},
+			project: "fallback: [gpt-4o-mini, gpt-4.1-mini]\n",
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, []string{"gpt-4o-mini", "gpt-4.1-mini"}, cfg.FallbackModels)
+			},
+		},
+		{
+			name:    "empty fallback entries dropped",
+			args:    []string{"-k", "sk-test", "-b", "gpt-4o, ,", "-b", ""},
+			project: "fallback: \"\"\n",
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, []string{"gpt-4o"}, cfg.FallbackModels)
+			},
+		},
+		{
+			name:    "empty fallback ignored",
+			args:    []string{"-k", "sk-test", "-b", ""},
+			project: "fallback: \"\"\n",
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, []string{"gpt-4.1-mini"}, cfg.FallbackModels)
+			},
+		},
+		{
+			name:    "unknown key warns",
+			args:    []string{"-k", "sk-test"},
+			project: "modle: gpt-4o\n",
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, "gpt-4o-mini", cfg.Model)
+			},
+		},
+		{
+			name:    "unknown key fails under strict",
+			args:    []string{"-k", "sk-test", "--strict"},
+			project: "modle: gpt-4o\n",
+			wantErr: true,
+		},
+		{
+			name:    "mistyped value warns and is ignored",
+			args:    []string{"-k", "sk-test"},
+			project: "temperature: hot\nstream: 5\ntop_p: 0.5\n",
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Nil(t, cfg.Temperature)
+				assert.False(t, cfg.Stream)
+				assert.Equal(t, 0.5, *cfg.TopP)
+			},
+		},
+		{
+			name:    "mistyped value fails under strict",
+			args:    []string{"-k", "sk-test", "--strict"},
+			project: "temperature: hot\n",
+			wantErr: true,
+		},
+		{
+			name:    "malformed url fails under strict",
+			args:    []string{"-k", "sk-test", "-u", "localhost:8080"},
+			env:     map[string]string{"AICLI_STRICT": "true"},
+			wantErr: true,
+		},
+		{
+			name: "env file from env chooses config file",
+			env:  map[string]string{"AICLI_ENV_FILE": "testdata/dotenv.env"},
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, "sk-from-env-file", cfg.APIKey)
+				assert.Equal(t, "gpt-4", cfg.Model)
+				assert.Equal(t, "testdata/dotenv.env", cfg.EnvFile)
+			},
+		},
+		{
+			name: "process env beats env file",
+			args: []string{"--env-file", "testdata/dotenv.env"},
+			env:  map[string]string{"AICLI_TEMPERATURE": "0.9"},
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, 0.9, *cfg.Temperature)
+			},
+		},
+		{
+			name:    "missing env file",
+			args:    []string{"-k", "sk-test", "--env-file", "testdata/missing.env"},
+			wantErr: true,
+		},
+		{
+			name:    "dotenv enabled in config",
+			project: "dotenv: true\n",
+			dotenv:  "AICLI_API_KEY=sk-dotenv\nAICLI_MODEL=dotenv-model\n",
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, "sk-dotenv", cfg.APIKey)
+				assert.Equal(t, "dotenv-model", cfg.Model)
+				assert.Equal(t, ".env", filepath.Base(cfg.EnvFile))
+			},
+		},
+		{
+			name:   "dotenv not read unless enabled",
+			args:   []string{"-k", "sk-test"},
+			dotenv: "AICLI_MODEL=dotenv-model\n",
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, "gpt-4o-mini", cfg.Model)
+				assert.Empty(t, cfg.EnvFile)
+			},
+		},
+		{
+			name:    "dotenv enabled without a .env",
+			args:    []string{"-k", "sk-test"},
+			project: "dotenv: true\n",
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Empty(t, cfg.EnvFile)
+			},
+		},
+		{
+			name:    "invalid auth mode",
+			args:    []string{"-k", "sk-test", "--auth", "basic"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid header",
+			args:    []string{"-k", "sk-test", "--header", "no-colon"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid fallback class in env",
+			args:    []string{"-k", "sk-test"},
+			env:     map[string]string{"AICLI_FALLBACK_ON": "server,bogus"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid protocol in env",
+			args:    []string{"-k", "sk-test"},
+			env:     map[string]string{"AICLI_PROTOCOL": "invalid"},
+			wantErr: true,
+		},
+		{
+			name: "registered protocol accepted",
+			args: []string{"-k", "sk-test", "-l", "anthropic"},
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, ProtocolAnthropic, cfg.Protocol)
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Clear all AICLI_* env vars
-			t.Setenv("AICLI_API_KEY", "")
-			t.Setenv("AICLI_API_KEY_FILE", "")
-			t.Setenv("AICLI_PROTOCOL", "")
-			t.Setenv("AICLI_URL", "")
-			t.Setenv("AICLI_MODEL", "")
-			t.Setenv("AICLI_FALLBACK", "")
-			t.Setenv("AICLI_SYSTEM", "")
-			t.Setenv("AICLI_CONFIG_FILE", "")
+			for _, o := range options {
+				t.Setenv(o.envName(), "")
+			}
+
+			_, _, project := isolateDiscovery(t)
+			captureWarnings(t)
+			if tt.project != "" {
+				writeFile(t, filepath.Join(project, ".aicli.yaml"), tt.project)
+			}
+			if tt.dotenv != "" {
+				writeFile(t, filepath.Join(project, ".env"), tt.dotenv)
+			}
 
 			// Apply test-specific env
 			for k, v := range tt.env {
@@ -1,11 +1,30 @@
 package config
 
+import "time"
+
+// defaultFallbackOn falls back on every error class except auth, since a bad
+// key fails the same way for every model sent with it. Auth errors still fall
+// back to a model on another provider, which has its own key.
+var defaultFallbackOn = []ErrorClass{
+	ErrorRateLimit,
+	ErrorServer,
+	ErrorContextLength,
+	ErrorParse,
+	ErrorNetwork,
+	ErrorRequest,
+}
+
 var defaultConfig = ConfigData{
 	StdinAsFile:    false,
 	Protocol:       ProtocolOpenAI,
 	URL:            "https://api.ppq.ai/chat/completions",
 	Model:          "gpt-4o-mini",
 	FallbackModels: []string{"gpt-4.1-mini"},
+	FallbackOn:     defaultFallbackOn,
+	Retries:        0,
+	RetryMaxWait:   30 * time.Second,
+	ConnectTimeout: 30 * time.Second,
+	Timeout:        5 * time.Minute,
 	Quiet:          false,
 	Verbose:        false,
 }
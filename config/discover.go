@@ -0,0 +1,84 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// projectConfigName is the per-project config file found by walking up from
+// the working directory.
+const projectConfigName = ".aicli.yaml"
+
+// systemConfigPath is the machine-wide config file. Tests point it elsewhere.
+var systemConfigPath = "/etc/aicli/config.yaml"
+
+// getwd returns the directory project config discovery starts from.
+var getwd = os.Getwd
+
+// configSearchPaths lists the config file locations in precedence order,
+// lowest first: system, user, then project. The project entry is the
+// nearest .aicli.yaml at or above the working directory, or the working
+// directory's own if there is none.
+func configSearchPaths() []string {
+	paths := []string{systemConfigPath}
+	if dir := userConfigDir(); dir != "" {
+		paths = append(paths, filepath.Join(dir, "aicli", "config.yaml"))
+	}
+	if wd, err := getwd(); err == nil {
+		paths = append(paths, findProjectConfig(wd))
+	}
+	return paths
+}
+
+// discoverConfigFiles returns the config files that exist, lowest precedence
+// first.
+func discoverConfigFiles() []string {
+	var found []string
+	for _, path := range configSearchPaths() {
+		if isFile(path) {
+			found = append(found, path)
+		}
+	}
+	return found
+}
+
+// userConfigDir is $XDG_CONFIG_HOME, or ~/.config when unset.
+func userConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config")
+}
+
+// findProjectConfig walks up from dir to the filesystem root and returns the
+// first .aicli.yaml found, or the candidate in dir when there is none.
+func findProjectConfig(dir string) string {
+	for d := dir; ; {
+		path := filepath.Join(d, projectConfigName)
+		if isFile(path) {
+			return path
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return filepath.Join(dir, projectConfigName)
+		}
+		d = parent
+	}
+}
+
+// isFile reports whether path exists and is not a directory. Other errors,
+// such as permission denied, count as present so that reading the file
+// reports them.
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	return err != nil || !info.IsDir()
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// isolateDiscovery points the system, user and project config locations at
+// empty temporary directories and returns them in that order.
+func isolateDiscovery(t *testing.T) (system, user, project string) {
+	t.Helper()
+	root := t.TempDir()
+	system = filepath.Join(root, "etc")
+	user = filepath.Join(root, "xdg")
+	project = filepath.Join(root, "repo", "sub")
+	for _, dir := range []string{system, filepath.Join(user, "aicli"), project} {
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+	}
+
+	oldSystem, oldGetwd := systemConfigPath, getwd
+	systemConfigPath = filepath.Join(system, "config.yaml")
+	getwd = func() (string, error) { return project, nil }
+	t.Setenv("XDG_CONFIG_HOME", user)
+	t.Cleanup(func() { systemConfigPath, getwd = oldSystem, oldGetwd })
+	return system, user, project
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestDiscoverConfigFiles(t *testing.T) {
+	system, user, project := isolateDiscovery(t)
+	repo := filepath.Dir(project)
+
+	assert.Empty(t, discoverConfigFiles())
+
+	systemFile := filepath.Join(system, "config.yaml")
+	userFile := filepath.Join(user, "aicli", "config.yaml")
+	repoFile := filepath.Join(repo, ".aicli.yaml")
+	writeFile(t, systemFile, "model: a\n")
+	writeFile(t, userFile, "model: b\n")
+	writeFile(t, repoFile, "model: c\n")
+	assert.Equal(t, []string{systemFile, userFile, repoFile}, discoverConfigFiles())
+
+	// The nearest project file wins over one further up
+	nearFile := filepath.Join(project, ".aicli.yaml")
+	writeFile(t, nearFile, "model: d\n")
+	assert.Equal(t, []string{systemFile, userFile, nearFile}, discoverConfigFiles())
+}
+
+func TestFindProjectConfigFallback(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, filepath.Join(dir, ".aicli.yaml"), findProjectConfig(dir))
+}
+
+func TestLoadConfigFilesLayers(t *testing.T) {
+	system, user, project := isolateDiscovery(t)
+	writeFile(t, filepath.Join(system, "config.yaml"), `
+model: system-model
+url: https://system.example.com
+fallback: system-fallback
+profiles:
+  fast:
+    model: system-fast
+providers:
+  local:
+    url: http://localhost:11434/api/chat
+`)
+	writeFile(t, filepath.Join(user, "aicli", "config.yaml"), `
+model: user-model
+key_file: ~/.aicli_key
+providers:
+  remote:
+    url: https://api.openai.com/v1/chat/completions
+`)
+	writeFile(t, filepath.Join(project, ".aicli.yaml"), `
+model: project-model
+prompt_file: prompts/review.txt
+default_profile: fast
+profiles:
+  review:
+    model: project-review
+`)
+
+	got, err := loadConfigFiles(discoverConfigFiles(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "system-fast", got.model)
+	assert.Equal(t, "fast", got.profile)
+	assert.Equal(t, "https://system.example.com", got.url)
+	assert.Equal(t, []string{"system-fallback"}, got.fallback)
+	home, _ := os.UserHomeDir()
+	assert.Equal(t, filepath.Join(home, ".aicli_key"), got.keyFile)
+	assert.Equal(t, filepath.Join(project, "prompts", "review.txt"), got.promptFile)
+	assert.Len(t, got.providers, 2)
+	assert.Equal(t, filepath.Join(system, "config.yaml")+":3", got.origins["url"].String())
+	assert.Equal(t, filepath.Join(user, "aicli", "config.yaml")+":5", got.origins["providers.remote"].String())
+	assert.Equal(t, filepath.Join(project, ".aicli.yaml")+":4", got.origins["profile"].String())
+
+	got, err = loadConfigFiles(discoverConfigFiles(), "review")
+	assert.NoError(t, err)
+	assert.Equal(t, "project-review", got.model)
+}
+
+func TestLoadConfigFilesLayersReplaceKeyGroups(t *testing.T) {
+	_, user, project := isolateDiscovery(t)
+	writeFile(t, filepath.Join(user, "aicli", "config.yaml"), `
+system: User prompt
+key: sk-user
+`)
+	writeFile(t, filepath.Join(project, ".aicli.yaml"), `
+system_file: sys.txt
+key_file: k
+`)
+
+	got, err := loadConfigFiles(discoverConfigFiles(), "")
+	assert.NoError(t, err)
+	assert.Empty(t, got.system)
+	assert.Empty(t, got.key)
+	assert.Equal(t, filepath.Join(project, "sys.txt"), got.systemFile)
+	assert.Equal(t, filepath.Join(project, "k"), got.keyFile)
+
+	writeFile(t, filepath.Join(project, ".aicli.yaml"), "key_cmd: pass show api\n")
+	got, err = loadConfigFiles(discoverConfigFiles(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "User prompt", got.system)
+	assert.Empty(t, got.key)
+	assert.Equal(t, "pass show api", got.keyCmd)
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultEnvFile is read from the working directory when dotenv is enabled
+// and no env file is named.
+const defaultEnvFile = ".env"
+
+// envFile is a parsed dotenv file.
+type envFile struct {
+	path   string
+	values map[string]string
+	lines  map[string]int // line each variable was set on
+}
+
+// readEnvFile reads a dotenv file: NAME=value lines, optionally prefixed by
+// "export". Blank lines and lines starting with # are skipped. Values may be
+// single-quoted (literal), double-quoted (with \n, \t, \" and \\ escapes),
+// or bare, where " #" starts a comment. Quoted values may span lines. A
+// variable set twice keeps the later value.
+func readEnvFile(path string) (*envFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read env file: %w", err)
+	}
+
+	f := &envFile{path: path, values: map[string]string{}, lines: map[string]int{}}
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(line, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			line = strings.TrimSpace(rest)
+		}
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !isEnvName(name) {
+			return nil, fmt.Errorf("%s:%d: expected NAME=value", path, lineNo)
+		}
+		value = strings.TrimLeft(value, " \t")
+
+		if value != "" && (value[0] == '"' || value[0] == '\'') {
+			// A quoted value runs to its closing quote, perhaps on a later line
+			quote := value[0]
+			text := value[1:]
+			end := closingQuote(text, quote)
+			for end < 0 && i+1 < len(lines) {
+				i++
+				text += "\n" + lines[i]
+				end = closingQuote(text, quote)
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("%s:%d: unterminated %c quote", path, lineNo, quote)
+			}
+			if rest := strings.TrimSpace(text[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("%s:%d: unexpected text after closing quote", path, lineNo)
+			}
+			value = text[:end]
+			if quote == '"' {
+				value = unescapeDouble(value)
+			}
+		} else {
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = value[:i]
+			}
+			value = strings.TrimSpace(value)
+		}
+
+		f.values[name] = value
+		f.lines[name] = lineNo
+	}
+	return f, nil
+}
+
+// lookup returns a variable's value from the process environment, or else
+// from the env file. fromFile reports which.
+func (f *envFile) lookup(name string) (value string, fromFile bool) {
+	if v := os.Getenv(name); v != "" || f == nil {
+		return v, false
+	}
+	v := f.values[name]
+	return v, v != ""
+}
+
+// defaultEnvPath is the working directory's .env.
+func defaultEnvPath() string {
+	if wd, err := getwd(); err == nil {
+		return filepath.Join(wd, defaultEnvFile)
+	}
+	return defaultEnvFile
+}
+
+// dotenvEnabled reports whether the highest layer that sets dotenv enables
+// it. Invalid values are reported when the layers are merged.
+func dotenvEnabled(layers ...sourceValues) bool {
+	for _, v := range layers {
+		if v.dotenv != "" {
+			enabled, _ := strconv.ParseBool(v.dotenv)
+			return enabled
+		}
+	}
+	return false
+}
+
+// closingQuote finds the quote that ends text, skipping backslash escapes in
+// double-quoted text. It returns -1 if there is none.
+func closingQuote(text string, quote byte) int {
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '\\' && quote == '"':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeDouble(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(s)
+}
+
+// isEnvName reports whether name is a valid environment variable name.
+func isEnvName(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for _, c := range name {
+		if c != '_' && (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadEnvFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		want        map[string]string
+		wantLines   map[string]int
+		errContains string
+	}{
+		{
+			name: "bare values, comments and export",
+			content: `# model settings
+AICLI_MODEL=gpt-4o
+export AICLI_RETRIES=3
+
+  AICLI_TIMEOUT = 2m  # generous
+AICLI_URL=http://localhost:8080/#frag
+`,
+			want: map[string]string{
+				"AICLI_MODEL":   "gpt-4o",
+				"AICLI_RETRIES": "3",
+				"AICLI_TIMEOUT": "2m",
+				"AICLI_URL":     "http://localhost:8080/#frag",
+			},
+			wantLines: map[string]int{"AICLI_MODEL": 2, "AICLI_RETRIES": 3, "AICLI_TIMEOUT": 5, "AICLI_URL": 6},
+		},
+		{
+			name: "quoted values",
+			content: `AICLI_SYSTEM="You are \"terse\".\tBe brief." # comment
+AICLI_DEFAULT_PROMPT='Review: $HOME \n stays'
+AICLI_STOP=""
+`,
+			want: map[string]string{
+				"AICLI_SYSTEM":         "You are \"terse\".\tBe brief.",
+				"AICLI_DEFAULT_PROMPT": `Review: $HOME \n stays`,
+				"AICLI_STOP":           "",
+			},
+		},
+		{
+			name: "multi-line values",
+			content: `AICLI_PROMPTS="first prompt
+second prompt"
+AICLI_HEADERS='X-Team: search
+X-Trace: 1'
+AICLI_MODEL=after
+`,
+			want: map[string]string{
+				"AICLI_PROMPTS": "first prompt\nsecond prompt",
+				"AICLI_HEADERS": "X-Team: search\nX-Trace: 1",
+				"AICLI_MODEL":   "after",
+			},
+			wantLines: map[string]int{"AICLI_PROMPTS": 1, "AICLI_HEADERS": 3, "AICLI_MODEL": 5},
+		},
+		{
+			name:    "later value wins and CRLF is accepted",
+			content: "AICLI_MODEL=a\r\nAICLI_MODEL=b\r\n",
+			want:    map[string]string{"AICLI_MODEL": "b"},
+		},
+		{
+			name:        "missing equals",
+			content:     "AICLI_MODEL\n",
+			errContains: ":1: expected NAME=value",
+		},
+		{
+			name:        "invalid name",
+			content:     "\n1AICLI=x\n",
+			errContains: ":2: expected NAME=value",
+		},
+		{
+			name:        "unterminated quote",
+			content:     "AICLI_SYSTEM=\"open\n",
+			errContains: ":1: unterminated \" quote",
+		},
+		{
+			name:        "text after quote",
+			content:     "AICLI_SYSTEM='a' b\n",
+			errContains: ":1: unexpected text after closing quote",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".env")
+			writeFile(t, path, tt.content)
+
+			got, err := readEnvFile(path)
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.values)
+			for name, line := range tt.wantLines {
+				assert.Equal(t, line, got.lines[name], name)
+			}
+		})
+	}
+}
+
+func TestLoadEnvironmentEnvFile(t *testing.T) {
+	for _, o := range options {
+		t.Setenv(o.envName(), "")
+	}
+	path := filepath.Join(t.TempDir(), ".env")
+	writeFile(t, path, "AICLI_MODEL=file-model\nAICLI_RETRIES=3\nAICLI_FILES=a.go,b.go\n")
+	t.Setenv("AICLI_MODEL", "process-model")
+
+	f, err := readEnvFile(path)
+	assert.NoError(t, err)
+	got := loadEnvironment(f)
+
+	assert.Equal(t, "process-model", got.model)
+	assert.Equal(t, "3", got.retries)
+	assert.Equal(t, []string{"a.go", "b.go"}, got.files)
+	assert.Equal(t, origins{
+		"retries": {kind: originEnvFile, name: "AICLI_RETRIES", path: path, line: 2},
+		"files":   {kind: originEnvFile, name: "AICLI_FILES", path: path, line: 3},
+	}, got.origins)
+
+	_, err = readEnvFile(filepath.Join(t.TempDir(), "missing.env"))
+	assert.ErrorContains(t, err, "read env file: open")
+}
@@ -1,34 +1,32 @@
 package config
 
-import "os"
 import "strings"
 
-func loadEnvironment() envValues {
-	ev := envValues{}
+// loadEnvironment reads the AICLI_* variable of every option from the
+// process environment, or from the env file f when the process leaves it
+// unset. f may be nil. List variables are split on the option's separator,
+// and paths are expanded.
+func loadEnvironment(f *envFile) sourceValues {
+	ev := sourceValues{}
 
-	if val := os.Getenv("AICLI_PROTOCOL"); val != "" {
-		ev.protocol = val
-	}
-	if val := os.Getenv("AICLI_URL"); val != "" {
-		ev.url = val
-	}
-	if val := os.Getenv("AICLI_API_KEY"); val != "" {
-		ev.key = val
-	} else if val := os.Getenv("AICLI_API_KEY_FILE"); val != "" {
-		content, err := os.ReadFile(val)
-		if err == nil {
-			ev.key = strings.TrimSpace(string(content))
+	for _, o := range options {
+		val, fromFile := f.lookup(o.envName())
+		if val == "" {
+			continue
+		}
+		if o.kind == kindList {
+			*o.list(&ev) = strings.Split(val, o.separator())
+		} else {
+			*o.text(&ev) = val
+		}
+		if fromFile {
+			if ev.origins == nil {
+				ev.origins = origins{}
+			}
+			ev.origins[o.key] = origin{kind: originEnvFile, name: o.envName(), path: f.path, line: f.lines[o.envName()]}
 		}
 	}
-	if val := os.Getenv("AICLI_MODEL"); val != "" {
-		ev.model = val
-	}
-	if val := os.Getenv("AICLI_FALLBACK"); val != "" {
-		ev.fallback = val
-	}
-	if val := os.Getenv("AICLI_SYSTEM"); val != "" {
-		ev.system = val
-	}
+	expandPaths(&ev, nil)
 
 	return ev
 }
@@ -9,42 +9,112 @@ func TestLoadEnvironment(t *testing.T) {
 	tests := []struct {
 		name string
 		env  map[string]string
-		want envValues
+		want sourceValues
 	}{
 		{
 			name: "empty environment",
 			env:  map[string]string{},
-			want: envValues{},
+			want: sourceValues{},
 		},
 		{
 			name: "protocol only",
 			env:  map[string]string{"AICLI_PROTOCOL": "ollama"},
-			want: envValues{protocol: "ollama"},
+			want: sourceValues{protocol: "ollama"},
 		},
 		{
 			name: "url only",
 			env:  map[string]string{"AICLI_URL": "http://localhost:11434"},
-			want: envValues{url: "http://localhost:11434"},
+			want: sourceValues{url: "http://localhost:11434"},
 		},
 		{
 			name: "api key direct",
 			env:  map[string]string{"AICLI_API_KEY": "sk-test123"},
-			want: envValues{key: "sk-test123"},
+			want: sourceValues{key: "sk-test123"},
 		},
 		{
 			name: "model only",
 			env:  map[string]string{"AICLI_MODEL": "llama3"},
-			want: envValues{model: "llama3"},
+			want: sourceValues{model: "llama3"},
 		},
 		{
 			name: "fallback only",
 			env:  map[string]string{"AICLI_FALLBACK": "gpt-3.5,gpt-4"},
-			want: envValues{fallback: "gpt-3.5,gpt-4"},
+			want: sourceValues{fallback: []string{"gpt-3.5", "gpt-4"}},
 		},
 		{
 			name: "system only",
 			env:  map[string]string{"AICLI_SYSTEM": "You are helpful"},
-			want: envValues{system: "You are helpful"},
+			want: sourceValues{system: "You are helpful"},
+		},
+		{
+			name: "fallback on",
+			env:  map[string]string{"AICLI_FALLBACK_ON": "server"},
+			want: sourceValues{fallbackOn: "server"},
+		},
+		{
+			name: "generation params",
+			env: map[string]string{
+				"AICLI_TEMPERATURE": "0.5",
+				"AICLI_TOP_P":       "0.9",
+				"AICLI_MAX_TOKENS":  "100",
+				"AICLI_SEED":        "1",
+				"AICLI_STOP":        "a,b",
+			},
+			want: sourceValues{generationValues: generationValues{
+				temperature: "0.5",
+				topP:        "0.9",
+				maxTokens:   "100",
+				seed:        "1",
+				stop:        []string{"a", "b"},
+			}},
+		},
+		{
+			name: "auth mode",
+			env:  map[string]string{"AICLI_AUTH": "query:key"},
+			want: sourceValues{auth: "query:key"},
+		},
+		{
+			name: "azure settings",
+			env: map[string]string{
+				"AICLI_ENDPOINT":    "https://corp.openai.azure.com",
+				"AICLI_DEPLOYMENT":  "prod",
+				"AICLI_API_VERSION": "2024-10-21",
+			},
+			want: sourceValues{endpoint: "https://corp.openai.azure.com", deployment: "prod", apiVersion: "2024-10-21"},
+		},
+		{
+			name: "transport settings",
+			env: map[string]string{
+				"AICLI_PROXY":           "http://proxy:3128",
+				"AICLI_CA_CERT":         "ca.pem",
+				"AICLI_CLIENT_CERT":     "client.pem",
+				"AICLI_CLIENT_KEY":      "client-key.pem",
+				"AICLI_CONNECT_TIMEOUT": "5s",
+				"AICLI_TIMEOUT":         "1m",
+			},
+			want: sourceValues{transportValues: transportValues{
+				proxy:          "http://proxy:3128",
+				caCert:         "ca.pem",
+				clientCert:     "client.pem",
+				clientKey:      "client-key.pem",
+				connectTimeout: "5s",
+				timeout:        "1m",
+			}},
+		},
+		{
+			name: "retry settings",
+			env:  map[string]string{"AICLI_RETRIES": "2", "AICLI_RETRY_MAX_WAIT": "45s"},
+			want: sourceValues{retries: "2", retryMaxWait: "45s"},
+		},
+		{
+			name: "stream enabled",
+			env:  map[string]string{"AICLI_STREAM": "true"},
+			want: sourceValues{stream: "true"},
+		},
+		{
+			name: "stream kept unparsed",
+			env:  map[string]string{"AICLI_STREAM": "maybe"},
+			want: sourceValues{stream: "maybe"},
 		},
 		{
 			name: "all variables set",
@@ -56,24 +126,24 @@ func TestLoadEnvironment(t *testing.T) {
 				"AICLI_FALLBACK": "gpt-3.5",
 				"AICLI_SYSTEM":   "system prompt",
 			},
-			want: envValues{
+			want: sourceValues{
 				protocol: "openai",
 				url:      "https://api.openai.com/v1/chat/completions",
 				key:      "sk-abc",
 				model:    "gpt-4",
-				fallback: "gpt-3.5",
+				fallback: []string{"gpt-3.5"},
 				system:   "system prompt",
 			},
 		},
 		{
 			name: "empty string values preserved",
 			env:  map[string]string{"AICLI_SYSTEM": ""},
-			want: envValues{system: ""},
+			want: sourceValues{system: ""},
 		},
 		{
 			name: "whitespace preserved",
 			env:  map[string]string{"AICLI_SYSTEM": "  spaces  "},
-			want: envValues{system: "  spaces  "},
+			want: sourceValues{system: "  spaces  "},
 		},
 	}
 
@@ -84,40 +154,54 @@ func TestLoadEnvironment(t *testing.T) {
 				t.Setenv(k, v)
 			}
 
-			got := loadEnvironment()
+			got := loadEnvironment(nil)
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
 
-func TestLoadEnvironmentKeyFile(t *testing.T) {
+func TestLoadEnvironmentLists(t *testing.T) {
 	tests := []struct {
 		name string
 		env  map[string]string
-		want envValues
+		want sourceValues
 	}{
 		{
-			name: "key file when no direct key",
-			env:  map[string]string{"AICLI_API_KEY_FILE": "testdata/api.key"},
-			want: envValues{key: "sk-test-key-123"},
+			name: "files split on commas",
+			env:  map[string]string{"AICLI_FILES": "a.go,b.go"},
+			want: sourceValues{files: []string{"a.go", "b.go"}},
 		},
 		{
-			name: "direct key overrides key file",
+			name: "prompts, headers and params split on newlines",
 			env: map[string]string{
-				"AICLI_API_KEY":      "sk-direct",
-				"AICLI_API_KEY_FILE": "testdata/api.key",
+				"AICLI_PROMPTS": "first, with a comma\nsecond",
+				"AICLI_HEADERS": "Accept: a, b\nX-Org: 1",
+				"AICLI_PARAMS":  "tags=[\"a\",\"b\"]",
+			},
+			want: sourceValues{
+				prompts: []string{"first, with a comma", "second"},
+				headers: []string{"Accept: a, b", "X-Org: 1"},
+				params:  []string{`tags=["a","b"]`},
 			},
-			want: envValues{key: "sk-direct"},
-		},
-		{
-			name: "key file not found",
-			env:  map[string]string{"AICLI_API_KEY_FILE": "/nonexistent/key.txt"},
-			want: envValues{},
 		},
 		{
-			name: "key file with whitespace trimmed",
-			env:  map[string]string{"AICLI_API_KEY_FILE": "testdata/api_whitespace.key"},
-			want: envValues{key: "sk-whitespace-key"},
+			name: "input and output settings",
+			env: map[string]string{
+				"AICLI_PROMPT_FILE":    "prompt.txt",
+				"AICLI_DEFAULT_PROMPT": "Summarize:",
+				"AICLI_SYSTEM_FILE":    "system.txt",
+				"AICLI_API_KEY_FILE":   "testdata/api.key",
+				"AICLI_OUTPUT":         "out.md",
+				"AICLI_QUIET":          "1",
+			},
+			want: sourceValues{
+				promptFile:    "prompt.txt",
+				defaultPrompt: "Summarize:",
+				systemFile:    "system.txt",
+				keyFile:       "testdata/api.key",
+				output:        "out.md",
+				quiet:         "1",
+			},
 		},
 	}
 
@@ -127,7 +211,7 @@ func TestLoadEnvironmentKeyFile(t *testing.T) {
 				t.Setenv(k, v)
 			}
 
-			got := loadEnvironment()
+			got := loadEnvironment(nil)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// applyParams sets each key=value pair into body. Dotted keys create nested
+// objects; values are decoded as JSON when possible and kept as strings
+// otherwise, so --param stop='["a"]' is a list and --param user=me a string.
+func applyParams(body map[string]interface{}, params []string, name string) (map[string]interface{}, error) {
+	for _, param := range params {
+		key, raw, ok := strings.Cut(param, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid %s: must be key=value, got: %s", name, param)
+		}
+
+		path := strings.Split(key, ".")
+		for _, part := range path {
+			if part == "" {
+				return nil, fmt.Errorf("invalid %s: empty path segment in %s", name, key)
+			}
+		}
+
+		if body == nil {
+			body = map[string]interface{}{}
+		}
+		setPath(body, path, parseParamValue(raw))
+	}
+	return body, nil
+}
+
+// parseParamValue decodes raw as JSON, keeping numbers exact.
+func parseParamValue(raw string) interface{} {
+	dec := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return raw
+	}
+	return v
+}
+
+// setPath stores value at path, replacing any non-object found on the way.
+func setPath(m map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := m[key].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[key] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
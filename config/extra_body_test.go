@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        map[string]interface{}
+		params      []string
+		want        map[string]interface{}
+		errContains string
+	}{
+		{
+			name: "no params",
+			want: nil,
+		},
+		{
+			name:   "string value",
+			params: []string{"reasoning_effort=high"},
+			want:   map[string]interface{}{"reasoning_effort": "high"},
+		},
+		{
+			name:   "json values",
+			params: []string{"n=2", "logprobs=true", `response_format={"type":"json_object"}`, `tags=["a","b"]`},
+			want: map[string]interface{}{
+				"n":               json.Number("2"),
+				"logprobs":        true,
+				"response_format": map[string]interface{}{"type": "json_object"},
+				"tags":            []interface{}{"a", "b"},
+			},
+		},
+		{
+			name:   "dotted path",
+			params: []string{"options.num_ctx=8192", "options.keep_alive=5m"},
+			want: map[string]interface{}{
+				"options": map[string]interface{}{
+					"num_ctx":    json.Number("8192"),
+					"keep_alive": "5m",
+				},
+			},
+		},
+		{
+			name: "merges into file body",
+			body: map[string]interface{}{
+				"options": map[string]interface{}{"num_ctx": 4096, "keep_alive": "1m"},
+			},
+			params: []string{"options.num_ctx=8192"},
+			want: map[string]interface{}{
+				"options": map[string]interface{}{"num_ctx": json.Number("8192"), "keep_alive": "1m"},
+			},
+		},
+		{
+			name:   "value containing equals",
+			params: []string{"user=a=b"},
+			want:   map[string]interface{}{"user": "a=b"},
+		},
+		{
+			name:   "trailing text is a string",
+			params: []string{"stop=1 2"},
+			want:   map[string]interface{}{"stop": "1 2"},
+		},
+		{
+			name:        "missing equals",
+			params:      []string{"temperature"},
+			errContains: "must be key=value",
+		},
+		{
+			name:        "empty segment",
+			params:      []string{"options..num_ctx=1"},
+			errContains: "empty path segment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyParams(tt.body, tt.params, "--param")
+			if tt.errContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
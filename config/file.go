@@ -1,44 +1,238 @@
 package config
 
 import (
+	"fmt"
 	"gopkg.in/yaml.v3"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 )
 
-func loadConfigFile(path string) (fileValues, error) {
-	if path == "" {
-		return fileValues{}, nil
+// namedSections are the file keys whose entries are merged by name across
+// config layers instead of being replaced as a whole.
+var namedSections = map[string]bool{"profiles": true, "providers": true}
+
+// loadConfigFiles reads the YAML configs at paths, lowest precedence first,
+// merges them, and applies the named profile. An empty profile selects the
+// merged default_profile. Relative paths are resolved against the directory
+// of the file that set them.
+func loadConfigFiles(paths []string, profile string) (sourceValues, error) {
+	if len(paths) == 0 {
+		if profile != "" {
+			return sourceValues{}, fmt.Errorf("profile %q requested but no config file was found", profile)
+		}
+		return sourceValues{}, nil
+	}
+
+	raw := map[string]interface{}{}
+	at := origins{}
+	var problems []string
+	for _, path := range paths {
+		layer, err := readConfigFile(path)
+		if err != nil {
+			return sourceValues{}, err
+		}
+		mergeLayer(raw, layer.values)
+		for key, line := range layer.lines {
+			at[key] = origin{kind: originFile, name: key, path: path, line: line}
+		}
+		problems = append(problems, layer.problems...)
+	}
+
+	fv := sourceValues{origins: at, problems: problems}
+	var err error
+	fv.profile, err = applyProfile(raw, profile)
+	if err != nil {
+		return sourceValues{}, err
+	}
+	if fv.profile != "" {
+		// Keys set by the profile point at their lines within it
+		prefix := "profiles." + fv.profile + "."
+		for key, o := range at {
+			if rest, ok := strings.CutPrefix(key, prefix); ok {
+				at[rest] = o
+			}
+		}
+		if profile == "" {
+			at["profile"] = at["default_profile"]
+		}
+	}
+
+	for _, o := range options {
+		v, ok := raw[o.key]
+		if !ok || o.noFile {
+			continue
+		}
+		if o.kind == kindList {
+			if list, ok := stringList(v); ok {
+				*o.list(&fv) = list
+			}
+		} else if s, ok := scalarString(v); ok {
+			*o.text(&fv) = s
+		}
+	}
+
+	// headers may also be a map of name to value
+	if v, ok := raw["headers"].(map[string]interface{}); ok {
+		fv.headers = headerList(v)
+	}
+	if v, ok := raw["extra_body"].(map[string]interface{}); ok {
+		fv.extraBody = v
+	}
+	if v, ok := raw["providers"].(map[string]interface{}); ok {
+		providers, err := parseProviders(v)
+		if err != nil {
+			return sourceValues{}, err
+		}
+		for name, pv := range providers {
+			if pv.keyFile != "" {
+				pv.keyFile = resolvePath(pv.keyFile, filepath.Dir(at.fileKey("providers."+name+".key_file").path))
+				providers[name] = pv
+			}
+		}
+		fv.providers = providers
+	}
+
+	// Relative paths are relative to the file that set them
+	expandPaths(&fv, func(o option) string { return filepath.Dir(at.fileKey(o.key).path) })
+
+	return fv, nil
+}
+
+// headerList turns a headers map into "Name: value" entries, sorted by name.
+func headerList(m map[string]interface{}) []string {
+	headers := make([]string, 0, len(m))
+	for name, value := range m {
+		if s, ok := scalarString(value); ok {
+			headers = append(headers, name+": "+s)
+		}
 	}
+	sort.Strings(headers)
+	return headers
+}
+
+// configFile is one decoded YAML config file.
+type configFile struct {
+	values   map[string]interface{}
+	lines    map[string]int // line of each key, by dotted path
+	problems []string       // unknown keys and mistyped values
+}
 
+// readConfigFile decodes and checks one YAML config file.
+func readConfigFile(path string) (configFile, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return fileValues{}, err
+		return configFile{}, err
 	}
 
-	var raw map[string]interface{}
-	if err := yaml.Unmarshal(data, &raw); err != nil {
-		return fileValues{}, err
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return configFile{}, fmt.Errorf("%s: %w", path, err)
 	}
 
-	fv := fileValues{}
-	if v, ok := raw["protocol"].(string); ok {
-		fv.protocol = v
+	f := configFile{lines: map[string]int{}}
+	if doc.Kind == 0 {
+		return f, nil
 	}
-	if v, ok := raw["url"].(string); ok {
-		fv.url = v
+	f.problems = checkConfigFile(path, &doc)
+	// A file that is not a map has been reported; its values are ignored
+	if err := doc.Decode(&f.values); err != nil && f.problems == nil {
+		return configFile{}, fmt.Errorf("%s: %w", path, err)
 	}
-	if v, ok := raw["key_file"].(string); ok {
-		fv.keyFile = v
+	keyLines(&doc, "", f.lines)
+	return f, nil
+}
+
+// mergeLayer overlays the keys of a higher-precedence config onto dst. Like
+// a profile, a layer that sets system_file or key_file clears the system or
+// key of lower layers.
+func mergeLayer(dst, layer map[string]interface{}) {
+	clearExclusive(dst, layer)
+	for key, value := range layer {
+		entries, isMap := value.(map[string]interface{})
+		existing, wasMap := dst[key].(map[string]interface{})
+		if !namedSections[key] || !isMap || !wasMap {
+			dst[key] = value
+			continue
+		}
+
+		merged := make(map[string]interface{}, len(existing)+len(entries))
+		for name, entry := range existing {
+			merged[name] = entry
+		}
+		for name, entry := range entries {
+			merged[name] = entry
+		}
+		dst[key] = merged
 	}
-	if v, ok := raw["model"].(string); ok {
-		fv.model = v
+}
+
+// scalarString renders a YAML scalar as text so numeric settings can be
+// written either bare (retries: 3) or quoted (retries: "3"). Bare dates such
+// as api_version: 2024-10-21 decode as timestamps and are rendered back.
+func scalarString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case int, float64, bool:
+		return fmt.Sprint(v), true
+	case time.Time:
+		if v.Equal(v.Truncate(24 * time.Hour)) {
+			return v.Format(time.DateOnly), true
+		}
+		return v.Format(time.RFC3339), true
+	}
+	return "", false
+}
+
+// stringList accepts either a single string or a list of scalars.
+func stringList(v interface{}) ([]string, bool) {
+	if s, ok := v.(string); ok {
+		return []string{s}, true
 	}
-	if v, ok := raw["fallback"].(string); ok {
-		fv.fallback = v
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, false
 	}
-	if v, ok := raw["system_file"].(string); ok {
-		fv.systemFile = v
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := scalarString(item); ok {
+			list = append(list, s)
+		}
 	}
+	return list, len(list) > 0
+}
 
-	return fv, nil
+// parseProviders reads the providers: map of name to settings.
+func parseProviders(raw map[string]interface{}) (map[string]providerValues, error) {
+	providers := make(map[string]providerValues, len(raw))
+	for name, v := range raw {
+		entry, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("providers.%s: must be a map", name)
+		}
+		if strings.Contains(name, ":") {
+			return nil, fmt.Errorf("providers.%s: name must not contain ':'", name)
+		}
+
+		var pv providerValues
+		pv.protocol, _ = entry["protocol"].(string)
+		pv.url, _ = entry["url"].(string)
+		pv.key, _ = entry["key"].(string)
+		pv.keyFile, _ = entry["key_file"].(string)
+		pv.auth, _ = entry["auth"].(string)
+		pv.apiVersion, _ = scalarString(entry["api_version"])
+		if headers, ok := entry["headers"].(map[string]interface{}); ok {
+			pv.headers = make(map[string]string, len(headers))
+			for header, value := range headers {
+				if s, ok := scalarString(value); ok {
+					pv.headers[header] = s
+				}
+			}
+		}
+		providers[name] = pv
+	}
+	return providers, nil
 }
@@ -2,45 +2,143 @@ package config
 
 import (
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestLoadConfigFile(t *testing.T) {
+	home, err := os.UserHomeDir()
+	assert.NoError(t, err)
+
 	tests := []struct {
 		name    string
 		path    string
-		want    fileValues
+		want    sourceValues
 		wantErr bool
 	}{
 		{
-			name: "empty path returns nil",
+			name: "no files returns nil",
 			path: "",
-			want: fileValues{},
+			want: sourceValues{},
 		},
 		{
 			name: "valid config",
 			path: "testdata/valid.yaml",
-			want: fileValues{
+			want: sourceValues{
 				protocol:   "ollama",
 				url:        "http://localhost:11434/api/chat",
-				keyFile:    "~/.aicli_key",
+				keyFile:    filepath.Join(home, ".aicli_key"),
 				model:      "llama3",
-				fallback:   "llama2,mistral",
-				systemFile: "~/system.txt",
+				fallback:   []string{"llama2,mistral"},
+				systemFile: filepath.Join(home, "system.txt"),
 			},
 		},
 		{
 			name: "partial config",
 			path: "testdata/partial.yaml",
-			want: fileValues{
+			want: sourceValues{
 				model:    "gpt-4",
-				fallback: "gpt-3.5-turbo",
+				fallback: []string{"gpt-3.5-turbo"},
+			},
+		},
+		{
+			name: "fallback and retry settings",
+			path: "testdata/fallback.yaml",
+			want: sourceValues{
+				fallbackOn:   "server,rate_limit",
+				retries:      "3",
+				retryMaxWait: "1m",
+			},
+		},
+		{
+			name: "generation params",
+			path: "testdata/generation.yaml",
+			want: sourceValues{generationValues: generationValues{
+				temperature: "0.7",
+				topP:        "0.95",
+				maxTokens:   "1024",
+				seed:        "7",
+				stop:        []string{"###", "END"},
+			}},
+		},
+		{
+			name: "extra body",
+			path: "testdata/extra_body.yaml",
+			want: sourceValues{extraBody: map[string]interface{}{
+				"keep_alive": "10m",
+				"options":    map[string]interface{}{"num_ctx": 8192},
+			}},
+		},
+		{
+			name: "auth and headers",
+			path: "testdata/headers.yaml",
+			want: sourceValues{
+				auth:    "header:api-key",
+				headers: []string{"OpenAI-Organization: org-123", "x-trace-id: abc"},
+			},
+		},
+		{
+			name: "azure settings",
+			path: "testdata/azure.yaml",
+			want: sourceValues{
+				protocol:   "azure",
+				endpoint:   "https://corp.openai.azure.com",
+				deployment: "gpt-4o-prod",
+				apiVersion: "2024-10-21",
+				fallback:   []string{"gpt-4o-mini-prod"},
+			},
+		},
+		{
+			name: "transport settings",
+			path: "testdata/transport.yaml",
+			want: sourceValues{transportValues: transportValues{
+				proxy:          "http://proxy.internal:3128",
+				caCert:         "/etc/ssl/corp-ca.pem",
+				clientCert:     "/etc/aicli/client.pem",
+				clientKey:      "/etc/aicli/client-key.pem",
+				connectTimeout: "10s",
+				timeout:        "2m",
+			}},
+		},
+		{
+			name: "named providers",
+			path: "testdata/providers.yaml",
+			want: sourceValues{
+				model:    "ollama:llama3",
+				fallback: []string{"openai:gpt-4o-mini"},
+				providers: map[string]providerValues{
+					"ollama": {
+						protocol: "ollama-chat",
+						url:      "http://localhost:11434/api/chat",
+						auth:     "none",
+					},
+					"openai": {
+						url:     "https://api.openai.com/v1/chat/completions",
+						keyFile: "testdata/api.key",
+						headers: map[string]string{"openai-organization": "org-123"},
+					},
+				},
+			},
+		},
+		{
+			name: "input, system and output settings",
+			path: "testdata/parity.yaml",
+			want: sourceValues{
+				files:         []string{"testdata/main.go", "testdata/util.go"},
+				prompts:       []string{"Review this code"},
+				defaultPrompt: "Summarize:",
+				system:        "You are a reviewer",
+				key:           "sk-file",
+				output:        "testdata/review.md",
+				quiet:         "true",
+				verbose:       "false",
 			},
 		},
 		{
 			name: "empty file",
 			path: "testdata/empty.yaml",
-			want: fileValues{},
+			want: sourceValues{},
 		},
 		{
 			name:    "file not found",
@@ -53,24 +151,72 @@ func TestLoadConfigFile(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "unknown keys ignored",
+			name: "unknown keys reported",
 			path: "testdata/unknown_keys.yaml",
-			want: fileValues{
+			want: sourceValues{
 				protocol: "openai",
 				model:    "gpt-4",
+				problems: []string{
+					`testdata/unknown_keys.yaml:3: unknown key "unknown_field"`,
+					`testdata/unknown_keys.yaml:4: unknown key "another_unknown"`,
+					`testdata/unknown_keys.yaml:5: unknown key "modle" (did you mean "model"?)`,
+				},
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := loadConfigFile(tt.path)
+			var paths []string
+			if tt.path != "" {
+				paths = []string{tt.path}
+			}
+			got, err := loadConfigFiles(paths, "")
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
 			}
 			assert.NoError(t, err)
+			got.origins = nil // see TestLoadConfigFileOrigins
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
+
+func TestLoadConfigFileOrigins(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		want    map[string]string
+	}{
+		{
+			name: "default profile",
+			want: map[string]string{
+				"model":       "testdata/profiles.yaml:1",
+				"system_file": "testdata/profiles.yaml:9",
+				"temperature": "testdata/profiles.yaml:10",
+				"profile":     "testdata/profiles.yaml:5",
+			},
+		},
+		{
+			name:    "requested profile",
+			profile: "creative",
+			want: map[string]string{
+				"url":         "testdata/profiles.yaml:2",
+				"model":       "testdata/profiles.yaml:12",
+				"system":      "testdata/profiles.yaml:13",
+				"temperature": "testdata/profiles.yaml:14",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadConfigFiles([]string{"testdata/profiles.yaml"}, tt.profile)
+			assert.NoError(t, err)
+			for key, want := range tt.want {
+				assert.Equal(t, want, got.origins[key].String(), key)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package config
 
-import "flag"
+import (
+	"flag"
+	"strconv"
+)
 
 type stringSlice []string
 
@@ -16,66 +19,51 @@ func (s *stringSlice) Set(value string) error {
 	return nil
 }
 
-func parseFlags(args []string) (flagValues, error) {
-	fv := flagValues{}
+// boolText is a boolean flag stored as text, so that an unset flag can be
+// told apart from false.
+type boolText string
 
-	fs := flag.NewFlagSet("aicli", flag.ContinueOnError)
-	fs.Usage = printUsage
-
-	var files stringSlice
-	var prompts stringSlice
+func (b *boolText) String() string { return string(*b) }
 
-	// Input flags
-	fs.Var(&files, "f", "")
-	fs.Var(&files, "file", "")
-	fs.Var(&prompts, "p", "")
-	fs.Var(&prompts, "prompt", "")
-	fs.StringVar(&fv.promptFile, "pf", "", "")
-	fs.StringVar(&fv.promptFile, "prompt-file", "", "")
-
-	// System flags
-	fs.StringVar(&fv.system, "s", "", "")
-	fs.StringVar(&fv.system, "system", "", "")
-	fs.StringVar(&fv.systemFile, "sf", "", "")
-	fs.StringVar(&fv.systemFile, "system-file", "", "")
+func (b *boolText) Set(value string) error {
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*b = boolText(strconv.FormatBool(v))
+	return nil
+}
 
-	// API flags
-	fs.StringVar(&fv.key, "k", "", "")
-	fs.StringVar(&fv.key, "key", "", "")
-	fs.StringVar(&fv.keyFile, "kf", "", "")
-	fs.StringVar(&fv.keyFile, "key-file", "", "")
-	fs.StringVar(&fv.protocol, "l", "", "")
-	fs.StringVar(&fv.protocol, "protocol", "", "")
-	fs.StringVar(&fv.url, "u", "", "")
-	fs.StringVar(&fv.url, "url", "", "")
+func (b *boolText) IsBoolFlag() bool { return true }
 
-	// Model flags
-	fs.StringVar(&fv.model, "m", "", "")
-	fs.StringVar(&fv.model, "model", "", "")
-	fs.StringVar(&fv.fallback, "b", "", "")
-	fs.StringVar(&fv.fallback, "fallback", "", "")
+func parseFlags(args []string) (sourceValues, error) {
+	fv := sourceValues{}
 
-	// Output flags
-	fs.StringVar(&fv.output, "o", "", "")
-	fs.StringVar(&fv.output, "output", "", "")
-	fs.StringVar(&fv.config, "c", "", "")
-	fs.StringVar(&fv.config, "config", "", "")
+	fs := flag.NewFlagSet("aicli", flag.ContinueOnError)
+	fs.Usage = printUsage
 
-	// Boolean flags
-	fs.BoolVar(&fv.stdinFile, "F", false, "")
-	fs.BoolVar(&fv.stdinFile, "stdin-file", false, "")
-	fs.BoolVar(&fv.quiet, "q", false, "")
-	fs.BoolVar(&fv.quiet, "quiet", false, "")
-	fs.BoolVar(&fv.verbose, "v", false, "")
-	fs.BoolVar(&fv.verbose, "verbose", false, "")
+	for _, o := range options {
+		names := []string{o.flagName()}
+		if o.short != "" {
+			names = append(names, o.short)
+		}
+		for _, name := range names {
+			switch o.kind {
+			case kindText:
+				fs.StringVar(o.text(&fv), name, "", "")
+			case kindBool:
+				fs.Var((*boolText)(o.text(&fv)), name, "")
+			case kindList:
+				fs.Var((*stringSlice)(o.list(&fv)), name, "")
+			}
+		}
+	}
 	fs.BoolVar(&fv.version, "version", false, "")
 
 	if err := fs.Parse(args); err != nil {
-		return flagValues{}, err
+		return sourceValues{}, err
 	}
-
-	fv.files = files
-	fv.prompts = prompts
+	expandPaths(&fv, nil)
 
 	return fv, nil
 }
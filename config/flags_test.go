@@ -15,187 +15,262 @@ func TestParseFlags(t *testing.T) {
 	tests := []struct {
 		name string
 		args []string
-		want flagValues
+		want sourceValues
 	}{
 		{
 			name: "empty args",
 			args: []string{},
-			want: flagValues{},
+			want: sourceValues{},
 		},
 		{
 			name: "single file short flag",
 			args: []string{"-f", "main.go"},
-			want: flagValues{files: []string{"main.go"}},
+			want: sourceValues{files: []string{"main.go"}},
 		},
 		{
 			name: "single file long flag",
 			args: []string{"--file", "main.go"},
-			want: flagValues{files: []string{"main.go"}},
+			want: sourceValues{files: []string{"main.go"}},
 		},
 		{
 			name: "multiple files",
 			args: []string{"-f", "a.go", "-f", "b.go", "--file", "c.go"},
-			want: flagValues{files: []string{"a.go", "b.go", "c.go"}},
+			want: sourceValues{files: []string{"a.go", "b.go", "c.go"}},
 		},
 		{
 			name: "single prompt short flag",
 			args: []string{"-p", "analyze this"},
-			want: flagValues{prompts: []string{"analyze this"}},
+			want: sourceValues{prompts: []string{"analyze this"}},
 		},
 		{
 			name: "single prompt long flag",
 			args: []string{"--prompt", "analyze this"},
-			want: flagValues{prompts: []string{"analyze this"}},
+			want: sourceValues{prompts: []string{"analyze this"}},
 		},
 		{
 			name: "multiple prompts",
 			args: []string{"-p", "first", "-p", "second", "--prompt", "third"},
-			want: flagValues{prompts: []string{"first", "second", "third"}},
+			want: sourceValues{prompts: []string{"first", "second", "third"}},
 		},
 		{
 			name: "prompt file",
 			args: []string{"-pf", "prompt.txt"},
-			want: flagValues{promptFile: "prompt.txt"},
+			want: sourceValues{promptFile: "prompt.txt"},
 		},
 		{
 			name: "prompt file long",
 			args: []string{"--prompt-file", "prompt.txt"},
-			want: flagValues{promptFile: "prompt.txt"},
+			want: sourceValues{promptFile: "prompt.txt"},
 		},
 		{
 			name: "system short",
 			args: []string{"-s", "You are helpful"},
-			want: flagValues{system: "You are helpful"},
+			want: sourceValues{system: "You are helpful"},
 		},
 		{
 			name: "system long",
 			args: []string{"--system", "You are helpful"},
-			want: flagValues{system: "You are helpful"},
+			want: sourceValues{system: "You are helpful"},
 		},
 		{
 			name: "system file short",
 			args: []string{"-sf", "system.txt"},
-			want: flagValues{systemFile: "system.txt"},
+			want: sourceValues{systemFile: "system.txt"},
 		},
 		{
 			name: "system file long",
 			args: []string{"--system-file", "system.txt"},
-			want: flagValues{systemFile: "system.txt"},
+			want: sourceValues{systemFile: "system.txt"},
 		},
 		{
 			name: "key short",
 			args: []string{"-k", "sk-abc123"},
-			want: flagValues{key: "sk-abc123"},
+			want: sourceValues{key: "sk-abc123"},
 		},
 		{
 			name: "key long",
 			args: []string{"--key", "sk-abc123"},
-			want: flagValues{key: "sk-abc123"},
+			want: sourceValues{key: "sk-abc123"},
 		},
 		{
 			name: "key file short",
 			args: []string{"-kf", "api.key"},
-			want: flagValues{keyFile: "api.key"},
+			want: sourceValues{keyFile: "api.key"},
 		},
 		{
 			name: "key file long",
 			args: []string{"--key-file", "api.key"},
-			want: flagValues{keyFile: "api.key"},
+			want: sourceValues{keyFile: "api.key"},
 		},
 		{
 			name: "protocol short",
 			args: []string{"-l", "ollama"},
-			want: flagValues{protocol: "ollama"},
+			want: sourceValues{protocol: "ollama"},
 		},
 		{
 			name: "protocol long",
 			args: []string{"--protocol", "ollama"},
-			want: flagValues{protocol: "ollama"},
+			want: sourceValues{protocol: "ollama"},
 		},
 		{
 			name: "url short",
 			args: []string{"-u", "http://localhost:11434"},
-			want: flagValues{url: "http://localhost:11434"},
+			want: sourceValues{url: "http://localhost:11434"},
 		},
 		{
 			name: "url long",
 			args: []string{"--url", "http://localhost:11434"},
-			want: flagValues{url: "http://localhost:11434"},
+			want: sourceValues{url: "http://localhost:11434"},
 		},
 		{
 			name: "model short",
 			args: []string{"-m", "gpt-4"},
-			want: flagValues{model: "gpt-4"},
+			want: sourceValues{model: "gpt-4"},
 		},
 		{
 			name: "model long",
 			args: []string{"--model", "gpt-4"},
-			want: flagValues{model: "gpt-4"},
+			want: sourceValues{model: "gpt-4"},
 		},
 		{
 			name: "fallback short",
 			args: []string{"-b", "gpt-3.5-turbo"},
-			want: flagValues{fallback: "gpt-3.5-turbo"},
+			want: sourceValues{fallback: []string{"gpt-3.5-turbo"}},
 		},
 		{
 			name: "fallback long",
 			args: []string{"--fallback", "gpt-3.5-turbo"},
-			want: flagValues{fallback: "gpt-3.5-turbo"},
+			want: sourceValues{fallback: []string{"gpt-3.5-turbo"}},
+		},
+		{
+			name: "fallback on",
+			args: []string{"--fallback-on", "server,rate_limit"},
+			want: sourceValues{fallbackOn: "server,rate_limit"},
+		},
+		{
+			name: "generation params",
+			args: []string{"--temperature", "0.5", "--top-p", "0.9", "--max-tokens", "100", "--seed", "1", "--stop", "a", "--stop", "b"},
+			want: sourceValues{generationValues: generationValues{
+				temperature: "0.5",
+				topP:        "0.9",
+				maxTokens:   "100",
+				seed:        "1",
+				stop:        []string{"a", "b"},
+			}},
+		},
+		{
+			name: "params",
+			args: []string{"--param", "n=2", "--param", "options.num_ctx=8192"},
+			want: sourceValues{params: []string{"n=2", "options.num_ctx=8192"}},
+		},
+		{
+			name: "auth and headers",
+			args: []string{"--auth", "none", "--header", "X-Org: 1", "--header", "X-Trace: 2"},
+			want: sourceValues{auth: "none", headers: []string{"X-Org: 1", "X-Trace: 2"}},
+		},
+		{
+			name: "azure settings",
+			args: []string{"--endpoint", "https://corp.openai.azure.com", "--deployment", "prod", "--api-version", "2024-10-21"},
+			want: sourceValues{endpoint: "https://corp.openai.azure.com", deployment: "prod", apiVersion: "2024-10-21"},
+		},
+		{
+			name: "transport settings",
+			args: []string{
+				"--proxy", "http://proxy:3128",
+				"--ca-cert", "ca.pem",
+				"--client-cert", "client.pem",
+				"--client-key", "client-key.pem",
+				"--connect-timeout", "5s",
+				"--timeout", "0",
+			},
+			want: sourceValues{transportValues: transportValues{
+				proxy:          "http://proxy:3128",
+				caCert:         "ca.pem",
+				clientCert:     "client.pem",
+				clientKey:      "client-key.pem",
+				connectTimeout: "5s",
+				timeout:        "0",
+			}},
+		},
+		{
+			name: "retries",
+			args: []string{"--retries", "3", "--retry-max-wait", "1m"},
+			want: sourceValues{retries: "3", retryMaxWait: "1m"},
 		},
 		{
 			name: "output short",
 			args: []string{"-o", "result.txt"},
-			want: flagValues{output: "result.txt"},
+			want: sourceValues{output: "result.txt"},
 		},
 		{
 			name: "output long",
 			args: []string{"--output", "result.txt"},
-			want: flagValues{output: "result.txt"},
+			want: sourceValues{output: "result.txt"},
 		},
 		{
 			name: "config short",
 			args: []string{"-c", "config.yaml"},
-			want: flagValues{config: "config.yaml"},
+			want: sourceValues{config: "config.yaml"},
 		},
 		{
 			name: "config long",
 			args: []string{"--config", "config.yaml"},
-			want: flagValues{config: "config.yaml"},
+			want: sourceValues{config: "config.yaml"},
+		},
+		{
+			name: "default prompt",
+			args: []string{"--default-prompt", "Summarize:"},
+			want: sourceValues{defaultPrompt: "Summarize:"},
+		},
+		{
+			name: "explicit false bool",
+			args: []string{"--stream=false"},
+			want: sourceValues{stream: "false"},
+		},
+		{
+			name: "profile",
+			args: []string{"--profile", "creative"},
+			want: sourceValues{profile: "creative"},
 		},
 		{
 			name: "stdin file short",
 			args: []string{"-F"},
-			want: flagValues{stdinFile: true},
+			want: sourceValues{stdinFile: "true"},
 		},
 		{
 			name: "stdin file long",
 			args: []string{"--stdin-file"},
-			want: flagValues{stdinFile: true},
+			want: sourceValues{stdinFile: "true"},
+		},
+		{
+			name: "stream",
+			args: []string{"--stream"},
+			want: sourceValues{stream: "true"},
 		},
 		{
 			name: "quiet short",
 			args: []string{"-q"},
-			want: flagValues{quiet: true},
+			want: sourceValues{quiet: "true"},
 		},
 		{
 			name: "quiet long",
 			args: []string{"--quiet"},
-			want: flagValues{quiet: true},
+			want: sourceValues{quiet: "true"},
 		},
 		{
 			name: "verbose short",
 			args: []string{"-v"},
-			want: flagValues{verbose: true},
+			want: sourceValues{verbose: "true"},
 		},
 		{
 			name: "verbose long",
 			args: []string{"--verbose"},
-			want: flagValues{verbose: true},
+			want: sourceValues{verbose: "true"},
 		},
 		{
 			name: "version flag",
 			args: []string{"--version"},
-			want: flagValues{version: true},
+			want: sourceValues{version: true},
 		},
 		{
 			name: "complex combination",
@@ -212,17 +287,17 @@ func TestParseFlags(t *testing.T) {
 				"-q",
 				"-v",
 			},
-			want: flagValues{
+			want: sourceValues{
 				files:      []string{"a.go", "b.go"},
 				prompts:    []string{"first prompt"},
 				promptFile: "prompt.txt",
 				system:     "system prompt",
 				key:        "key123",
 				model:      "gpt-4",
-				fallback:   "gpt-3.5",
+				fallback:   []string{"gpt-3.5"},
 				output:     "out.txt",
-				quiet:      true,
-				verbose:    true,
+				quiet:      "true",
+				verbose:    "true",
 			},
 		},
 	}
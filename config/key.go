@@ -0,0 +1,98 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// resolveKey returns the API key from the highest layer that sets one and
+// records where it came from. Within a layer a direct key beats a key file,
+// which beats a key command; the files and commands of other layers are
+// never read or run.
+func resolveKey(layers []layer, at origins) (string, error) {
+	for i := len(layers) - 1; i >= 0; i-- {
+		l := layers[i]
+		switch {
+		case l.values.key != "":
+			at["key"] = l.origin(lookupOption("key"))
+			return l.values.key, nil
+		case l.values.keyFile != "":
+			at["key"] = l.origin(lookupOption("key_file"))
+			return readKeyFile(l.values.keyFile, at["key"])
+		case l.values.keyCmd != "":
+			at["key"] = l.origin(lookupOption("key_cmd"))
+			return runKeyCommand(l.values.keyCmd, at["key"])
+		}
+	}
+	return "", nil
+}
+
+// readKeyFile reads a key file set by from, warning if others can read it.
+func readKeyFile(path string, from origin) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read key file from %s: %w", from, err)
+	}
+	key := strings.TrimSpace(string(content))
+	if key == "" {
+		return "", fmt.Errorf("key file %s from %s is empty", path, from)
+	}
+
+	// Windows does not report Unix permissions
+	if info, err := os.Stat(path); err == nil && runtime.GOOS != "windows" && info.Mode().Perm()&0o044 != 0 {
+		fmt.Fprintf(warningOutput, "warning: key file %s from %s is readable by group or others (mode %04o); restrict it with chmod 600\n",
+			path, from, info.Mode().Perm())
+	}
+	return key, nil
+}
+
+// keyCmdTimeout bounds how long a key command may run, long enough for a
+// password manager to prompt for its passphrase. Tests shorten it.
+var keyCmdTimeout = 30 * time.Second
+
+// runKeyCommand runs command through the shell and returns the first line
+// of its output as the API key.
+func runKeyCommand(command string, from origin) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), keyCmdTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := shellCommand(ctx, command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// stdin stays unset so the command cannot consume the prompt, and
+	// WaitDelay stops a child left behind by the shell holding the pipes
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("key command from %s timed out after %s: %s", from, keyCmdTimeout, command)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("key command from %s failed: %w: %s", from, err, msg)
+		}
+		return "", fmt.Errorf("key command from %s failed: %w", from, err)
+	}
+
+	key, _, _ := strings.Cut(strings.TrimLeft(stdout.String(), "\r\n"), "\n")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("key command from %s printed no key: %s", from, command)
+	}
+	return key, nil
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRunKeyCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		want        string
+		errContains string
+	}{
+		{
+			name:    "key from stdout",
+			command: "echo sk-cmd-key",
+			want:    "sk-cmd-key",
+		},
+		{
+			name:    "first line only",
+			command: "printf '\\n  sk-cmd-key  \\nlogin: me\\n'",
+			want:    "sk-cmd-key",
+		},
+		{
+			name:        "failure includes stderr",
+			command:     "echo 'gpg: decryption failed' >&2; exit 2",
+			errContains: "key command from flag --key-cmd failed: exit status 2: gpg: decryption failed",
+		},
+		{
+			name:        "no output",
+			command:     "true",
+			errContains: "key command from flag --key-cmd printed no key: true",
+		},
+		{
+			name:        "timeout",
+			command:     "sleep 5",
+			errContains: "key command from flag --key-cmd timed out after 100ms: sleep 5",
+		},
+	}
+
+	old := keyCmdTimeout
+	keyCmdTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { keyCmdTimeout = old })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runKeyCommand(tt.command, origin{kind: originFlag, name: "--key-cmd"})
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadKeyFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no Unix permissions")
+	}
+	from := origin{kind: originFlag, name: "--key-file"}
+	dir := t.TempDir()
+
+	private := filepath.Join(dir, "private.key")
+	assert.NoError(t, os.WriteFile(private, []byte("sk-private\n"), 0600))
+	shared := filepath.Join(dir, "shared.key")
+	assert.NoError(t, os.WriteFile(shared, []byte("sk-shared\n"), 0600))
+	assert.NoError(t, os.Chmod(shared, 0640))
+
+	warnings := captureWarnings(t)
+	key, err := readKeyFile(private, from)
+	assert.NoError(t, err)
+	assert.Equal(t, "sk-private", key)
+	assert.Empty(t, warnings.String())
+
+	key, err = readKeyFile(shared, from)
+	assert.NoError(t, err)
+	assert.Equal(t, "sk-shared", key)
+	assert.Equal(t, "warning: key file "+shared+" from flag --key-file is readable by group or others (mode 0640); restrict it with chmod 600\n", warnings.String())
+}
@@ -1,112 +1,142 @@
 package config
 
 import (
+	"fmt"
+	"net/textproto"
 	"os"
 	"strings"
 )
 
-func mergeSources(flags flagValues, env envValues, file fileValues) ConfigData {
+// layer is one source of settings, with how it spells an option in errors
+// and where it says an option came from.
+type layer struct {
+	values sourceValues
+	nameOf func(option) string
+	origin func(option) origin
+}
+
+// mergeSources layers the file, env and flag values over the defaults, each
+// overriding the last. It also returns where each setting came from.
+func mergeSources(flags, env, file sourceValues) (ConfigData, origins, error) {
 	cfg := defaultConfig
+	cfg.ExtraBody = file.extraBody
+	cfg.Profile = file.profile
 
-	// Apply file values
-	if file.protocol != "" {
-		cfg.Protocol = parseProtocol(file.protocol)
-	}
-	if file.url != "" {
-		cfg.URL = file.url
+	at := origins{}
+	fileLayer := layer{file, fileOptionName(file), fileOrigin(file)}
+	if file.extraBody != nil {
+		at["extra_body"] = fileLayer.origin(lookupOption("extra_body"))
 	}
-	if file.model != "" {
-		cfg.Model = file.model
-	}
-	if file.fallback != "" {
-		cfg.FallbackModels = strings.Split(file.fallback, ",")
+	for name := range file.providers {
+		at["providers."+name] = file.origins.lookup("providers." + name)
 	}
 
-	// Apply env values
-	if env.protocol != "" {
-		cfg.Protocol = parseProtocol(env.protocol)
-	}
-	if env.url != "" {
-		cfg.URL = env.url
-	}
-	if env.model != "" {
-		cfg.Model = env.model
-	}
-	if env.fallback != "" {
-		cfg.FallbackModels = strings.Split(env.fallback, ",")
-	}
-	if env.system != "" {
-		cfg.SystemPrompt = env.system
-	}
-	if env.key != "" {
-		cfg.APIKey = env.key
+	layers := []layer{
+		fileLayer,
+		{env, envOptionName, envOrigin(env)},
+		{flags, flagOptionName, flagOrigin},
+	}
+	for _, l := range layers {
+		if err := applyOptions(&cfg, l.values, l.nameOf); err != nil {
+			return ConfigData{}, nil, err
+		}
+		recordOrigins(at, l.values, l.origin)
 	}
 
-	// Apply flag values
-	if flags.protocol != "" {
-		cfg.Protocol = parseProtocol(flags.protocol)
-	}
-	if flags.url != "" {
-		cfg.URL = flags.url
+	// Azure addresses the resource endpoint and names deployments as models
+	if cfg.Protocol == ProtocolAzure {
+		if cfg.Endpoint != "" {
+			cfg.URL = cfg.Endpoint
+			at["url"] = at["endpoint"]
+		}
+		if cfg.Deployment != "" {
+			cfg.Model = cfg.Deployment
+			at["model"] = at["deployment"]
+		}
 	}
-	if flags.model != "" {
-		cfg.Model = flags.model
+
+	var err error
+	cfg.Providers, err = buildProviders(file.providers, cfg.ModelEntries(), file.origins)
+	if err != nil {
+		return ConfigData{}, nil, err
 	}
-	if flags.fallback != "" {
-		cfg.FallbackModels = strings.Split(flags.fallback, ",")
+	cfg.SystemPrompt, err = resolveSystem(layers, at)
+	if err != nil {
+		return ConfigData{}, nil, err
 	}
-	if flags.output != "" {
-		cfg.Output = flags.output
+	cfg.APIKey, err = resolveKey(layers, at)
+	if err != nil {
+		return ConfigData{}, nil, err
 	}
-	cfg.Quiet = flags.quiet
-	cfg.Verbose = flags.verbose
-	cfg.StdinAsFile = flags.stdinFile
 
-	// Collect input paths
-	cfg.FilePaths = flags.files
-	cfg.PromptFlags = flags.prompts
-	if flags.promptFile != "" {
-		cfg.PromptPaths = []string{flags.promptFile}
-	}
+	return cfg, at, nil
+}
 
-	// Resolve system prompt (direct > file)
-	if flags.system != "" {
-		cfg.SystemPrompt = flags.system
-	} else if flags.systemFile != "" {
-		content, err := os.ReadFile(flags.systemFile)
-		if err == nil {
-			cfg.SystemPrompt = strings.TrimRight(string(content), "\n")
+// resolveSystem returns the system prompt from the highest layer that sets
+// one and records where it came from. Within a layer the direct value beats
+// the file.
+func resolveSystem(layers []layer, at origins) (string, error) {
+	for i := len(layers) - 1; i >= 0; i-- {
+		l := layers[i]
+		switch {
+		case l.values.system != "":
+			at["system"] = l.origin(lookupOption("system"))
+			return l.values.system, nil
+		case l.values.systemFile != "":
+			at["system"] = l.origin(lookupOption("system_file"))
+			content, err := os.ReadFile(l.values.systemFile)
+			if err != nil {
+				return "", fmt.Errorf("read system file from %s: %w", at["system"], err)
+			}
+			return strings.TrimRight(string(content), "\n"), nil
 		}
-	} else if file.systemFile != "" && cfg.SystemPrompt == "" {
-		content, err := os.ReadFile(file.systemFile)
-		if err == nil {
-			cfg.SystemPrompt = strings.TrimRight(string(content), "\n")
+	}
+	return "", nil
+}
+
+// parseErrorClasses splits a comma-separated class list. Unknown names are
+// kept so validateConfig can report them.
+func parseErrorClasses(s string) []ErrorClass {
+	var classes []ErrorClass
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			classes = append(classes, ErrorClass(name))
 		}
 	}
+	return classes
+}
+
+// applyAuth parses an auth mode from one source: bearer, none, header:NAME
+// or query:NAME. An empty value is unset.
+func applyAuth(cfg *ConfigData, value, sourceName string) error {
+	if value == "" {
+		return nil
+	}
 
-	// Resolve API key (direct > file)
-	if flags.key != "" {
-		cfg.APIKey = flags.key
-	} else if flags.keyFile != "" {
-		content, err := os.ReadFile(flags.keyFile)
-		if err == nil {
-			cfg.APIKey = strings.TrimSpace(string(content))
+	mode, name, _ := strings.Cut(value, ":")
+	switch AuthMode(mode) {
+	case AuthBearer, AuthNone:
+		if name != "" {
+			return fmt.Errorf("invalid %s: %s takes no name, got: %s", sourceName, mode, value)
 		}
-	} else if cfg.APIKey == "" && file.keyFile != "" {
-		content, err := os.ReadFile(file.keyFile)
-		if err == nil {
-			cfg.APIKey = strings.TrimSpace(string(content))
+	case AuthHeader, AuthQuery:
+		if name == "" {
+			return fmt.Errorf("invalid %s: %s needs a name, as in %s:X-API-Key, got: %s", sourceName, mode, mode, value)
 		}
+	default:
+		return fmt.Errorf("invalid %s: must be bearer, header:NAME, query:NAME or none, got: %s", sourceName, value)
 	}
 
-	return cfg
+	cfg.Auth = AuthMode(mode)
+	cfg.AuthName = name
+	return nil
 }
 
-func parseProtocol(s string) APIProtocol {
-	switch s {
-	case "ollama":
-		return ProtocolOllama
-	default:
-		return ProtocolOpenAI
+// setHeader adds a custom header. Names are canonicalized so the same header
+// from two sources overrides rather than duplicates.
+func setHeader(cfg *ConfigData, name, value string) {
+	if cfg.Headers == nil {
+		cfg.Headers = map[string]string{}
 	}
+	cfg.Headers[textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))] = strings.TrimSpace(value)
 }
@@ -1,30 +1,34 @@
 package config
 
 import (
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestMergeSources(t *testing.T) {
 	tests := []struct {
 		name  string
-		flags flagValues
-		env   envValues
-		file  fileValues
+		flags sourceValues
+		env   sourceValues
+		file  sourceValues
 		want  ConfigData
 	}{
 		{
 			name:  "all empty uses defaults",
-			flags: flagValues{},
-			env:   envValues{},
-			file:  fileValues{},
+			flags: sourceValues{},
+			env:   sourceValues{},
+			file:  sourceValues{},
 			want:  defaultConfig,
 		},
 		{
 			name:  "file overrides defaults",
-			flags: flagValues{},
-			env:   envValues{},
-			file: fileValues{
+			flags: sourceValues{},
+			env:   sourceValues{},
+			file: sourceValues{
 				protocol: "ollama",
 				model:    "llama3",
 			},
@@ -33,15 +37,35 @@ func TestMergeSources(t *testing.T) {
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "llama3",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
+			},
+		},
+		{
+			name:  "ollama chat protocol",
+			flags: sourceValues{protocol: "ollama-chat"},
+			env:   sourceValues{},
+			file:  sourceValues{},
+			want: ConfigData{
+				Protocol:       ProtocolOllamaChat,
+				URL:            "https://api.ppq.ai/chat/completions",
+				Model:          "gpt-4o-mini",
+				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 			},
 		},
 		{
 			name:  "env overrides file",
-			flags: flagValues{},
-			env: envValues{
+			flags: sourceValues{},
+			env: sourceValues{
 				model: "gpt-4",
 			},
-			file: fileValues{
+			file: sourceValues{
 				model: "llama3",
 			},
 			want: ConfigData{
@@ -49,124 +73,173 @@ func TestMergeSources(t *testing.T) {
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 			},
 		},
 		{
 			name: "flags override env",
-			flags: flagValues{
+			flags: sourceValues{
 				model: "claude-3",
 			},
-			env: envValues{
+			env: sourceValues{
 				model: "gpt-4",
 			},
-			file: fileValues{},
+			file: sourceValues{},
 			want: ConfigData{
 				Protocol:       ProtocolOpenAI,
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "claude-3",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 			},
 		},
 		{
 			name: "full precedence chain",
-			flags: flagValues{
+			flags: sourceValues{
 				protocol: "ollama",
-				quiet:    true,
+				quiet:    "true",
 			},
-			env: envValues{
+			env: sourceValues{
 				protocol: "openai",
 				model:    "gpt-4",
 				url:      "http://custom.api",
 			},
-			file: fileValues{
+			file: sourceValues{
 				protocol: "openai",
 				model:    "llama3",
 				url:      "http://file.api",
-				fallback: "mistral",
+				fallback: []string{"mistral"},
 			},
 			want: ConfigData{
 				Protocol:       ProtocolOllama,
 				URL:            "http://custom.api",
 				Model:          "gpt-4",
 				FallbackModels: []string{"mistral"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				Quiet:          true,
 			},
 		},
 		{
 			name: "fallback string split",
-			flags: flagValues{
-				fallback: "model1,model2,model3",
+			flags: sourceValues{
+				fallback: []string{"model1,model2,model3"},
 			},
-			env:  envValues{},
-			file: fileValues{},
+			env:  sourceValues{},
+			file: sourceValues{},
 			want: ConfigData{
 				Protocol:       ProtocolOpenAI,
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"model1", "model2", "model3"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 			},
 		},
 		{
 			name: "direct key flag",
-			flags: flagValues{
+			flags: sourceValues{
 				key: "sk-direct",
 			},
-			env:  envValues{},
-			file: fileValues{},
+			env:  sourceValues{},
+			file: sourceValues{},
 			want: ConfigData{
 				Protocol:       ProtocolOpenAI,
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				APIKey:         "sk-direct",
 			},
 		},
 		{
 			name: "direct system flag",
-			flags: flagValues{
+			flags: sourceValues{
 				system: "You are helpful",
 			},
-			env:  envValues{},
-			file: fileValues{},
+			env:  sourceValues{},
+			file: sourceValues{},
 			want: ConfigData{
 				Protocol:       ProtocolOpenAI,
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				SystemPrompt:   "You are helpful",
 			},
 		},
 		{
 			name: "file paths collected",
-			flags: flagValues{
+			flags: sourceValues{
 				files:      []string{"a.go", "b.go"},
 				prompts:    []string{"prompt1", "prompt2"},
 				promptFile: "prompt.txt",
 			},
-			env:  envValues{},
-			file: fileValues{},
+			env:  sourceValues{},
+			file: sourceValues{},
 			want: ConfigData{
 				Protocol:       ProtocolOpenAI,
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				FilePaths:      []string{"a.go", "b.go"},
 				PromptFlags:    []string{"prompt1", "prompt2"},
 				PromptPaths:    []string{"prompt.txt"},
 			},
 		},
+		{
+			name:  "stream from file",
+			flags: sourceValues{},
+			env:   sourceValues{},
+			file:  sourceValues{stream: "true"},
+			want: ConfigData{
+				Protocol:       ProtocolOpenAI,
+				URL:            "https://api.ppq.ai/chat/completions",
+				Model:          "gpt-4o-mini",
+				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
+				Stream:         true,
+			},
+		},
 		{
 			name: "stdin file flag",
-			flags: flagValues{
-				stdinFile: true,
+			flags: sourceValues{
+				stdinFile: "true",
 			},
-			env:  envValues{},
-			file: fileValues{},
+			env:  sourceValues{},
+			file: sourceValues{},
 			want: ConfigData{
 				Protocol:       ProtocolOpenAI,
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				StdinAsFile:    true,
 			},
 		},
@@ -174,40 +247,46 @@ func TestMergeSources(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := mergeSources(tt.flags, tt.env, tt.file)
+			got, _, err := mergeSources(tt.flags, tt.env, tt.file)
+			assert.NoError(t, err)
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
 
 func TestMergeSourcesKeyFile(t *testing.T) {
+	captureWarnings(t)
 	tests := []struct {
 		name  string
-		flags flagValues
-		env   envValues
-		file  fileValues
+		flags sourceValues
+		env   sourceValues
+		file  sourceValues
 		want  ConfigData
 	}{
 		{
 			name: "key file from flags",
-			flags: flagValues{
+			flags: sourceValues{
 				keyFile: "testdata/api.key",
 			},
-			env:  envValues{},
-			file: fileValues{},
+			env:  sourceValues{},
+			file: sourceValues{},
 			want: ConfigData{
 				Protocol:       ProtocolOpenAI,
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				APIKey:         "sk-test-key-123",
 			},
 		},
 		{
 			name:  "key file from file config",
-			flags: flagValues{},
-			env:   envValues{},
-			file: fileValues{
+			flags: sourceValues{},
+			env:   sourceValues{},
+			file: sourceValues{
 				keyFile: "testdata/api.key",
 			},
 			want: ConfigData{
@@ -215,32 +294,40 @@ func TestMergeSourcesKeyFile(t *testing.T) {
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				APIKey:         "sk-test-key-123",
 			},
 		},
 		{
 			name: "direct key overrides key file",
-			flags: flagValues{
+			flags: sourceValues{
 				key:     "sk-direct",
 				keyFile: "testdata/api.key",
 			},
-			env:  envValues{},
-			file: fileValues{},
+			env:  sourceValues{},
+			file: sourceValues{},
 			want: ConfigData{
 				Protocol:       ProtocolOpenAI,
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				APIKey:         "sk-direct",
 			},
 		},
 		{
 			name:  "env key overrides file key file",
-			flags: flagValues{},
-			env: envValues{
+			flags: sourceValues{},
+			env: sourceValues{
 				key: "sk-env",
 			},
-			file: fileValues{
+			file: sourceValues{
 				keyFile: "testdata/api.key",
 			},
 			want: ConfigData{
@@ -248,21 +335,63 @@ func TestMergeSourcesKeyFile(t *testing.T) {
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				APIKey:         "sk-env",
 			},
 		},
+		{
+			name:  "key file from env",
+			flags: sourceValues{},
+			env:   sourceValues{keyFile: "testdata/api.key"},
+			file:  sourceValues{key: "sk-file"},
+			want: ConfigData{
+				Protocol:       ProtocolOpenAI,
+				URL:            "https://api.ppq.ai/chat/completions",
+				Model:          "gpt-4o-mini",
+				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
+				APIKey:         "sk-test-key-123",
+			},
+		},
+		{
+			name:  "flag key file overrides env key",
+			flags: sourceValues{keyFile: "testdata/api.key"},
+			env:   sourceValues{key: "sk-env"},
+			file:  sourceValues{},
+			want: ConfigData{
+				Protocol:       ProtocolOpenAI,
+				URL:            "https://api.ppq.ai/chat/completions",
+				Model:          "gpt-4o-mini",
+				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
+				APIKey:         "sk-test-key-123",
+			},
+		},
 		{
 			name: "key file with whitespace trimmed",
-			flags: flagValues{
+			flags: sourceValues{
 				keyFile: "testdata/api_whitespace.key",
 			},
-			env:  envValues{},
-			file: fileValues{},
+			env:  sourceValues{},
+			file: sourceValues{},
 			want: ConfigData{
 				Protocol:       ProtocolOpenAI,
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				APIKey:         "sk-whitespace-key",
 			},
 		},
@@ -270,7 +399,8 @@ func TestMergeSourcesKeyFile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := mergeSources(tt.flags, tt.env, tt.file)
+			got, _, err := mergeSources(tt.flags, tt.env, tt.file)
+			assert.NoError(t, err)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -279,31 +409,35 @@ func TestMergeSourcesKeyFile(t *testing.T) {
 func TestMergeSourcesSystemFile(t *testing.T) {
 	tests := []struct {
 		name  string
-		flags flagValues
-		env   envValues
-		file  fileValues
+		flags sourceValues
+		env   sourceValues
+		file  sourceValues
 		want  ConfigData
 	}{
 		{
 			name: "system file from flags",
-			flags: flagValues{
+			flags: sourceValues{
 				systemFile: "testdata/system.txt",
 			},
-			env:  envValues{},
-			file: fileValues{},
+			env:  sourceValues{},
+			file: sourceValues{},
 			want: ConfigData{
 				Protocol:       ProtocolOpenAI,
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				SystemPrompt:   "You are a helpful assistant.",
 			},
 		},
 		{
 			name:  "system file from file config",
-			flags: flagValues{},
-			env:   envValues{},
-			file: fileValues{
+			flags: sourceValues{},
+			env:   sourceValues{},
+			file: sourceValues{
 				systemFile: "testdata/system.txt",
 			},
 			want: ConfigData{
@@ -311,32 +445,40 @@ func TestMergeSourcesSystemFile(t *testing.T) {
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				SystemPrompt:   "You are a helpful assistant.",
 			},
 		},
 		{
 			name: "direct system overrides system file",
-			flags: flagValues{
+			flags: sourceValues{
 				system:     "Direct system",
 				systemFile: "testdata/system.txt",
 			},
-			env:  envValues{},
-			file: fileValues{},
+			env:  sourceValues{},
+			file: sourceValues{},
 			want: ConfigData{
 				Protocol:       ProtocolOpenAI,
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				SystemPrompt:   "Direct system",
 			},
 		},
 		{
 			name:  "env system overrides file system file",
-			flags: flagValues{},
-			env: envValues{
+			flags: sourceValues{},
+			env: sourceValues{
 				system: "System from env",
 			},
-			file: fileValues{
+			file: sourceValues{
 				systemFile: "testdata/system.txt",
 			},
 			want: ConfigData{
@@ -344,21 +486,29 @@ func TestMergeSourcesSystemFile(t *testing.T) {
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				SystemPrompt:   "System from env",
 			},
 		},
 		{
 			name: "empty system file",
-			flags: flagValues{
+			flags: sourceValues{
 				systemFile: "testdata/system_empty.txt",
 			},
-			env:  envValues{},
-			file: fileValues{},
+			env:  sourceValues{},
+			file: sourceValues{},
 			want: ConfigData{
 				Protocol:       ProtocolOpenAI,
 				URL:            "https://api.ppq.ai/chat/completions",
 				Model:          "gpt-4o-mini",
 				FallbackModels: []string{"gpt-4.1-mini"},
+				FallbackOn:     defaultFallbackOn,
+				RetryMaxWait:   30 * time.Second,
+				ConnectTimeout: 30 * time.Second,
+				Timeout:        5 * time.Minute,
 				SystemPrompt:   "",
 			},
 		},
@@ -366,8 +516,744 @@ func TestMergeSourcesSystemFile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := mergeSources(tt.flags, tt.env, tt.file)
+			got, _, err := mergeSources(tt.flags, tt.env, tt.file)
+			assert.NoError(t, err)
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
+
+func TestMergeSourcesRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		flags       sourceValues
+		env         sourceValues
+		file        sourceValues
+		wantRetries int
+		wantMaxWait time.Duration
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:        "defaults",
+			wantRetries: 0,
+			wantMaxWait: 30 * time.Second,
+		},
+		{
+			name:        "file values",
+			file:        sourceValues{retries: "3", retryMaxWait: "1m"},
+			wantRetries: 3,
+			wantMaxWait: time.Minute,
+		},
+		{
+			name:        "env overrides file",
+			env:         sourceValues{retries: "5"},
+			file:        sourceValues{retries: "3", retryMaxWait: "1m"},
+			wantRetries: 5,
+			wantMaxWait: time.Minute,
+		},
+		{
+			name:        "flag zero overrides env",
+			flags:       sourceValues{retries: "0", retryMaxWait: "2s"},
+			env:         sourceValues{retries: "5", retryMaxWait: "10s"},
+			wantRetries: 0,
+			wantMaxWait: 2 * time.Second,
+		},
+		{
+			name:        "invalid flag retries",
+			flags:       sourceValues{retries: "many"},
+			wantErr:     true,
+			errContains: "invalid --retries",
+		},
+		{
+			name:        "negative env retries",
+			env:         sourceValues{retries: "-1"},
+			wantErr:     true,
+			errContains: "invalid AICLI_RETRIES",
+		},
+		{
+			name:        "invalid file max wait",
+			file:        sourceValues{retryMaxWait: "soon"},
+			wantErr:     true,
+			errContains: "invalid config retry_max_wait",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := mergeSources(tt.flags, tt.env, tt.file)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRetries, got.Retries)
+			assert.Equal(t, tt.wantMaxWait, got.RetryMaxWait)
+		})
+	}
+}
+
+func TestMergeSourcesFallbackOn(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags sourceValues
+		env   sourceValues
+		file  sourceValues
+		want  []ErrorClass
+	}{
+		{
+			name: "default excludes auth",
+			want: defaultFallbackOn,
+		},
+		{
+			name: "file value",
+			file: sourceValues{fallbackOn: "server, rate_limit"},
+			want: []ErrorClass{ErrorServer, ErrorRateLimit},
+		},
+		{
+			name: "env overrides file",
+			env:  sourceValues{fallbackOn: "auth"},
+			file: sourceValues{fallbackOn: "server"},
+			want: []ErrorClass{ErrorAuth},
+		},
+		{
+			name:  "flag overrides env",
+			flags: sourceValues{fallbackOn: "parse,network"},
+			env:   sourceValues{fallbackOn: "auth"},
+			want:  []ErrorClass{ErrorParse, ErrorNetwork},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := mergeSources(tt.flags, tt.env, tt.file)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.FallbackOn)
+		})
+	}
+}
+
+func TestMergeSourcesGeneration(t *testing.T) {
+	seed := int64(9)
+
+	tests := []struct {
+		name        string
+		flags       sourceValues
+		env         sourceValues
+		file        sourceValues
+		want        ConfigData
+		errContains string
+	}{
+		{
+			name: "unset by default",
+			want: ConfigData{},
+		},
+		{
+			name: "flags override env override file",
+			flags: sourceValues{generationValues: generationValues{
+				temperature: "0.1",
+			}},
+			env: sourceValues{generationValues: generationValues{
+				temperature: "0.5",
+				topP:        "0.8",
+				stop:        []string{"env"},
+			}},
+			file: sourceValues{generationValues: generationValues{
+				temperature: "1.0",
+				topP:        "0.9",
+				maxTokens:   "512",
+				seed:        "9",
+				stop:        []string{"file"},
+			}},
+			want: ConfigData{
+				Temperature: floatPtr(0.1),
+				TopP:        floatPtr(0.8),
+				MaxTokens:   intPtr(512),
+				Seed:        &seed,
+				Stop:        []string{"env"},
+			},
+		},
+		{
+			name: "zero temperature is kept",
+			flags: sourceValues{generationValues: generationValues{
+				temperature: "0",
+			}},
+			want: ConfigData{Temperature: floatPtr(0)},
+		},
+		{
+			name: "invalid flag temperature",
+			flags: sourceValues{generationValues: generationValues{
+				temperature: "hot",
+			}},
+			errContains: "invalid --temperature",
+		},
+		{
+			name: "invalid env max tokens",
+			env: sourceValues{generationValues: generationValues{
+				maxTokens: "1.5",
+			}},
+			errContains: "invalid AICLI_MAX_TOKENS",
+		},
+		{
+			name: "invalid file seed",
+			file: sourceValues{generationValues: generationValues{
+				seed: "abc",
+			}},
+			errContains: "invalid config seed",
+		},
+		{
+			name: "invalid file seed names its line",
+			file: sourceValues{
+				generationValues: generationValues{seed: "abc"},
+				origins:          origins{"seed": {kind: originFile, name: "seed", path: "/home/me/.aicli.yaml", line: 7}},
+			},
+			errContains: "invalid seed (/home/me/.aicli.yaml:7): must be an integer, got: abc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := mergeSources(tt.flags, tt.env, tt.file)
+			if tt.errContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.Temperature, got.Temperature)
+			assert.Equal(t, tt.want.TopP, got.TopP)
+			assert.Equal(t, tt.want.MaxTokens, got.MaxTokens)
+			assert.Equal(t, tt.want.Seed, got.Seed)
+			assert.Equal(t, tt.want.Stop, got.Stop)
+		})
+	}
+}
+
+func TestMergeSourcesAuth(t *testing.T) {
+	tests := []struct {
+		name         string
+		flags        sourceValues
+		env          sourceValues
+		file         sourceValues
+		wantAuth     AuthMode
+		wantAuthName string
+		wantHeaders  map[string]string
+		errContains  string
+	}{
+		{
+			name:     "protocol default",
+			wantAuth: AuthDefault,
+		},
+		{
+			name:         "file header mode",
+			file:         sourceValues{auth: "header:api-key"},
+			wantAuth:     AuthHeader,
+			wantAuthName: "api-key",
+		},
+		{
+			name:     "flag overrides env",
+			flags:    sourceValues{auth: "none"},
+			env:      sourceValues{auth: "query:key"},
+			wantAuth: AuthNone,
+		},
+		{
+			name:  "headers merged with flags winning",
+			flags: sourceValues{headers: []string{"x-trace-id: flag", "X-Extra:  spaced  "}},
+			file:  sourceValues{headers: []string{"X-Trace-Id: file", "OpenAI-Organization: org-1"}},
+			wantHeaders: map[string]string{
+				"X-Trace-Id":          "flag",
+				"Openai-Organization": "org-1",
+				"X-Extra":             "spaced",
+			},
+		},
+		{
+			name:        "unknown mode",
+			env:         sourceValues{auth: "basic"},
+			errContains: "invalid AICLI_AUTH",
+		},
+		{
+			name:        "header mode without name",
+			flags:       sourceValues{auth: "header"},
+			errContains: "needs a name",
+		},
+		{
+			name:        "bearer with name",
+			file:        sourceValues{auth: "bearer:x"},
+			errContains: "takes no name",
+		},
+		{
+			name:        "malformed header flag",
+			flags:       sourceValues{headers: []string{"X-Org"}},
+			errContains: "invalid --header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := mergeSources(tt.flags, tt.env, tt.file)
+			if tt.errContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAuth, got.Auth)
+			assert.Equal(t, tt.wantAuthName, got.AuthName)
+			assert.Equal(t, tt.wantHeaders, got.Headers)
+		})
+	}
+}
+
+func TestMergeSourcesAzure(t *testing.T) {
+	tests := []struct {
+		name       string
+		flags      sourceValues
+		env        sourceValues
+		file       sourceValues
+		wantURL    string
+		wantModel  string
+		wantAPIVer string
+	}{
+		{
+			name: "endpoint and deployment replace url and model",
+			file: sourceValues{
+				protocol:   "azure",
+				endpoint:   "https://corp.openai.azure.com",
+				deployment: "gpt-4o-prod",
+				apiVersion: "2024-10-21",
+			},
+			wantURL:    "https://corp.openai.azure.com",
+			wantModel:  "gpt-4o-prod",
+			wantAPIVer: "2024-10-21",
+		},
+		{
+			name:  "deployment wins over model flag",
+			flags: sourceValues{model: "ignored", deployment: "from-flag"},
+			env: sourceValues{
+				protocol: "azure",
+				endpoint: "https://env.openai.azure.com",
+			},
+			wantURL:   "https://env.openai.azure.com",
+			wantModel: "from-flag",
+		},
+		{
+			name: "ignored for other protocols",
+			file: sourceValues{
+				endpoint:   "https://corp.openai.azure.com",
+				deployment: "gpt-4o-prod",
+			},
+			wantURL:   "https://api.ppq.ai/chat/completions",
+			wantModel: "gpt-4o-mini",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := mergeSources(tt.flags, tt.env, tt.file)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantURL, got.URL)
+			assert.Equal(t, tt.wantModel, got.Model)
+			assert.Equal(t, tt.wantAPIVer, got.APIVersion)
+		})
+	}
+}
+
+func TestMergeSourcesTransport(t *testing.T) {
+	tests := []struct {
+		name        string
+		flags       sourceValues
+		env         sourceValues
+		file        sourceValues
+		wantProxy   string
+		wantConnect time.Duration
+		wantTimeout time.Duration
+		errContains string
+	}{
+		{
+			name:        "defaults",
+			wantConnect: 30 * time.Second,
+			wantTimeout: 5 * time.Minute,
+		},
+		{
+			name: "flags override env override file",
+			flags: sourceValues{transportValues: transportValues{
+				timeout: "0",
+			}},
+			env: sourceValues{transportValues: transportValues{
+				proxy:          "http://env:3128",
+				connectTimeout: "5s",
+			}},
+			file: sourceValues{transportValues: transportValues{
+				proxy:          "http://file:3128",
+				connectTimeout: "10s",
+				timeout:        "2m",
+			}},
+			wantProxy:   "http://env:3128",
+			wantConnect: 5 * time.Second,
+			wantTimeout: 0,
+		},
+		{
+			name: "invalid connect timeout",
+			env: sourceValues{transportValues: transportValues{
+				connectTimeout: "soon",
+			}},
+			errContains: "invalid AICLI_CONNECT_TIMEOUT: must be a duration such as 30s, got: soon",
+		},
+		{
+			name: "negative timeout",
+			flags: sourceValues{transportValues: transportValues{
+				timeout: "-1s",
+			}},
+			errContains: "invalid --timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := mergeSources(tt.flags, tt.env, tt.file)
+			if tt.errContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantProxy, got.Proxy)
+			assert.Equal(t, tt.wantConnect, got.ConnectTimeout)
+			assert.Equal(t, tt.wantTimeout, got.Timeout)
+		})
+	}
+}
+
+func TestMergeSourcesProviders(t *testing.T) {
+	captureWarnings(t)
+	tests := []struct {
+		name        string
+		file        sourceValues
+		want        map[string]ProviderConfig
+		errContains string
+	}{
+		{
+			name: "none configured",
+		},
+		{
+			name: "protocol defaults to openai and key file is read",
+			file: sourceValues{model: "openai:gpt-4o", providers: map[string]providerValues{
+				"openai": {
+					url:     "https://api.openai.com/v1/chat/completions",
+					keyFile: "testdata/api.key",
+					headers: map[string]string{"openai-organization": "org-123"},
+				},
+			}},
+			want: map[string]ProviderConfig{
+				"openai": {
+					Protocol: ProtocolOpenAI,
+					URL:      "https://api.openai.com/v1/chat/completions",
+					APIKey:   "sk-test-key-123",
+					Headers:  map[string]string{"Openai-Organization": "org-123"},
+				},
+			},
+		},
+		{
+			name: "top-level key file is not inherited",
+			file: sourceValues{
+				keyFile: "testdata/api.key",
+				providers: map[string]providerValues{
+					"local": {protocol: "ollama-chat", url: "http://localhost:11434/api/chat", auth: "none"},
+				},
+			},
+			want: map[string]ProviderConfig{
+				"local": {
+					Protocol: ProtocolOllamaChat,
+					URL:      "http://localhost:11434/api/chat",
+					Auth:     AuthNone,
+				},
+			},
+		},
+		{
+			name: "unused provider key file is not read",
+			file: sourceValues{model: "local:llama3", providers: map[string]providerValues{
+				"local": {url: "http://localhost:11434/api/chat", auth: "none"},
+				"stale": {url: "https://api.example.com", keyFile: "testdata/missing.key"},
+			}},
+			want: map[string]ProviderConfig{
+				"local": {Protocol: ProtocolOpenAI, URL: "http://localhost:11434/api/chat", Auth: AuthNone},
+				"stale": {Protocol: ProtocolOpenAI, URL: "https://api.example.com"},
+			},
+		},
+		{
+			name: "invalid auth",
+			file: sourceValues{providers: map[string]providerValues{
+				"odd": {url: "http://localhost", auth: "cookie"},
+			}},
+			errContains: "invalid providers.odd.auth",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := mergeSources(sourceValues{}, sourceValues{}, tt.file)
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Providers)
+		})
+	}
+}
+
+func TestMergeSourcesEveryLayer(t *testing.T) {
+	tests := []struct {
+		name        string
+		flags       sourceValues
+		env         sourceValues
+		file        sourceValues
+		check       func(*testing.T, ConfigData)
+		errContains string
+	}{
+		{
+			name: "input settings from file",
+			file: sourceValues{
+				files:         []string{"a.go"},
+				prompts:       []string{"Review"},
+				promptFile:    "prompt.txt",
+				defaultPrompt: "Summarize:",
+				stdinFile:     "true",
+			},
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, []string{"a.go"}, cfg.FilePaths)
+				assert.Equal(t, []string{"Review"}, cfg.PromptFlags)
+				assert.Equal(t, []string{"prompt.txt"}, cfg.PromptPaths)
+				assert.Equal(t, "Summarize:", cfg.DefaultPrompt)
+				assert.True(t, cfg.StdinAsFile)
+			},
+		},
+		{
+			name:  "flag files replace file files",
+			flags: sourceValues{files: []string{"b.go"}},
+			file:  sourceValues{files: []string{"a.go"}},
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, []string{"b.go"}, cfg.FilePaths)
+			},
+		},
+		{
+			name: "output settings from env",
+			env:  sourceValues{output: "out.md", quiet: "1", verbose: "true"},
+			file: sourceValues{stream: "true"},
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, "out.md", cfg.Output)
+				assert.True(t, cfg.Quiet)
+				assert.True(t, cfg.Verbose)
+				assert.True(t, cfg.Stream)
+			},
+		},
+		{
+			name: "env false overrides file true",
+			env:  sourceValues{stream: "false"},
+			file: sourceValues{stream: "true"},
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.False(t, cfg.Stream)
+			},
+		},
+		{
+			name: "system text from file",
+			file: sourceValues{system: "From file", systemFile: "testdata/system.txt"},
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, "From file", cfg.SystemPrompt)
+			},
+		},
+		{
+			name: "env system file overrides file system text",
+			env:  sourceValues{systemFile: "testdata/system.txt"},
+			file: sourceValues{system: "From file"},
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, "You are a helpful assistant.", cfg.SystemPrompt)
+			},
+		},
+		{
+			name: "env params merge into file extra body",
+			env:  sourceValues{params: []string{"options.num_ctx=4096"}},
+			file: sourceValues{extraBody: map[string]interface{}{"keep_alive": "10m"}},
+			check: func(t *testing.T, cfg ConfigData) {
+				assert.Equal(t, map[string]interface{}{
+					"keep_alive": "10m",
+					"options":    map[string]interface{}{"num_ctx": json.Number("4096")},
+				}, cfg.ExtraBody)
+			},
+		},
+		{
+			name:        "invalid bool",
+			env:         sourceValues{quiet: "maybe"},
+			errContains: "invalid AICLI_QUIET: must be true or false",
+		},
+		{
+			name:        "invalid env param",
+			env:         sourceValues{params: []string{"novalue"}},
+			errContains: "invalid AICLI_PARAMS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := mergeSources(tt.flags, tt.env, tt.file)
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestMergeSourcesOrigins(t *testing.T) {
+	captureWarnings(t)
+	file := sourceValues{
+		model:     "file-model",
+		url:       "https://file.example.com",
+		key:       "file-key",
+		extraBody: map[string]interface{}{"keep_alive": "10m"},
+		providers: map[string]providerValues{"local": {url: "http://localhost:11434/api/chat", auth: "none"}},
+		origins: origins{
+			"model":           {kind: originFile, name: "model", path: "/etc/aicli/config.yaml", line: 2},
+			"url":             {kind: originFile, name: "url", path: "/etc/aicli/config.yaml", line: 3},
+			"providers.local": {kind: originFile, name: "providers.local", path: "/etc/aicli/config.yaml", line: 7},
+		},
+	}
+	env := sourceValues{model: "env-model", params: []string{"seed=1"}}
+	flags := sourceValues{keyFile: "testdata/api.key", stream: "true"}
+
+	_, got, err := mergeSources(flags, env, file)
+	assert.NoError(t, err)
+
+	want := map[string]string{
+		"model":           "env AICLI_MODEL",
+		"url":             "/etc/aicli/config.yaml:3",
+		"key":             "flag --key-file",
+		"extra_body":      "env AICLI_PARAMS",
+		"stream":          "flag --stream",
+		"providers.local": "/etc/aicli/config.yaml:7",
+		"retries":         "default",
+	}
+	for key, origin := range want {
+		assert.Equal(t, origin, got.lookup(key).String(), key)
+	}
+}
+
+func TestMergeSourcesKeyCmd(t *testing.T) {
+	captureWarnings(t)
+	tests := []struct {
+		name        string
+		flags       sourceValues
+		env         sourceValues
+		file        sourceValues
+		wantKey     string
+		wantOrigin  string
+		errContains string
+	}{
+		{
+			name:       "key command from config",
+			file:       sourceValues{keyCmd: "echo sk-from-cmd"},
+			wantKey:    "sk-from-cmd",
+			wantOrigin: "config key_cmd",
+		},
+		{
+			name:       "flag key command beats env key",
+			flags:      sourceValues{keyCmd: "echo sk-from-cmd"},
+			env:        sourceValues{key: "sk-env"},
+			wantKey:    "sk-from-cmd",
+			wantOrigin: "flag --key-cmd",
+		},
+		{
+			name:       "env key beats config key command, which does not run",
+			env:        sourceValues{key: "sk-env"},
+			file:       sourceValues{keyCmd: "exit 1"},
+			wantKey:    "sk-env",
+			wantOrigin: "env AICLI_API_KEY",
+		},
+		{
+			name:       "key file beats key command in the same layer",
+			env:        sourceValues{keyFile: "testdata/api.key", keyCmd: "exit 1"},
+			wantKey:    "sk-test-key-123",
+			wantOrigin: "env AICLI_API_KEY_FILE",
+		},
+		{
+			name:        "failure is reported",
+			env:         sourceValues{keyCmd: "exit 3"},
+			errContains: "key command from env AICLI_API_KEY_CMD failed: exit status 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, at, err := mergeSources(tt.flags, tt.env, tt.file)
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantKey, got.APIKey)
+			assert.Equal(t, tt.wantOrigin, at.lookup("key").String())
+		})
+	}
+}
+
+func TestMergeSourcesReadErrors(t *testing.T) {
+	captureWarnings(t)
+	empty := filepath.Join(t.TempDir(), "empty.key")
+	assert.NoError(t, os.WriteFile(empty, nil, 0600))
+
+	tests := []struct {
+		name        string
+		flags       sourceValues
+		env         sourceValues
+		file        sourceValues
+		errContains string
+	}{
+		{
+			name:        "missing key file from flag",
+			flags:       sourceValues{keyFile: "testdata/missing.key"},
+			errContains: "read key file from flag --key-file: open testdata/missing.key: no such file or directory",
+		},
+		{
+			name:        "missing key file from env",
+			env:         sourceValues{keyFile: "testdata/missing.key"},
+			errContains: "read key file from env AICLI_API_KEY_FILE: open testdata/missing.key",
+		},
+		{
+			name:        "empty key file",
+			env:         sourceValues{keyFile: empty},
+			errContains: "key file " + empty + " from env AICLI_API_KEY_FILE is empty",
+		},
+		{
+			name: "missing system file from config file",
+			file: sourceValues{
+				systemFile: "testdata/missing.txt",
+				origins:    origins{"system_file": {kind: originFile, path: "/home/me/.aicli.yaml", line: 4}},
+			},
+			errContains: "read system file from /home/me/.aicli.yaml:4: open testdata/missing.txt",
+		},
+		{
+			name:        "missing provider key file",
+			file:        sourceValues{fallback: []string{"local:llama3"}, providers: map[string]providerValues{"local": {url: "http://localhost", keyFile: "testdata/missing.key"}}},
+			errContains: "read key file from config providers.local.key_file: open testdata/missing.key",
+		},
+		{
+			name:  "overridden files are not read",
+			flags: sourceValues{key: "sk-flag", system: "From flag"},
+			file:  sourceValues{keyFile: "testdata/missing.key", systemFile: "testdata/missing.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := mergeSources(tt.flags, tt.env, tt.file)
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,564 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// optionKind selects how an option's value is read from each source.
+type optionKind int
+
+const (
+	// kindText is a single value.
+	kindText optionKind = iota
+	// kindBool is true or false; as a flag it takes no argument.
+	kindBool
+	// kindList is repeatable as a flag, split on listSep in the environment,
+	// and a YAML list or single string in the config file.
+	kindList
+)
+
+// option describes one setting and how each source spells it. Every option
+// is a flag and an environment variable, and a config file key unless
+// noFile is set.
+type option struct {
+	key      string // config file key; the flag and env names derive from it
+	flag     string // long flag, when it differs from key
+	short    string // short flag
+	env      string // environment variable, when it differs from AICLI_KEY
+	kind     optionKind
+	listSep  string // env separator for kindList (default ",")
+	noFile   bool
+	path     bool   // a file path, expanded by expandPaths
+	arg      string // flag argument in the usage text
+	usage    string // flag description; further lines continue it
+	envUsage string // env description, when it differs from usage's first line
+
+	// text and list locate the option's field in sourceValues
+	text func(*sourceValues) *string
+	list func(*sourceValues) *[]string
+
+	// apply and applyList parse a value into cfg. name spells the option as
+	// its source does, for errors. Both are nil for options that mergeSources
+	// resolves itself.
+	apply     func(cfg *ConfigData, value, name string) error
+	applyList func(cfg *ConfigData, values []string, name string) error
+
+	// show renders the merged value for config show; nil hides the option
+	show func(cfg ConfigData) string
+}
+
+// optionSection groups options under a heading in the usage text.
+type optionSection struct {
+	title   string
+	options []option
+	note    string // printed after the options
+}
+
+// optionSections is the option schema. Flags, environment variables, config
+// file keys and the usage text are all generated from it.
+var optionSections = []optionSection{
+	{title: "Input", options: []option{
+		{key: "files", flag: "file", short: "f", kind: kindList, path: true, arg: "PATH",
+			usage:     "input file (repeatable)",
+			envUsage:  "comma-separated input files",
+			list:      func(v *sourceValues) *[]string { return &v.files },
+			show:      func(c ConfigData) string { return showList(c.FilePaths) },
+			applyList: setList(func(c *ConfigData) *[]string { return &c.FilePaths })},
+		{key: "prompts", flag: "prompt", short: "p", kind: kindList, listSep: "\n", arg: "TEXT",
+			usage:     "prompt text (repeatable)",
+			envUsage:  "prompt text, one prompt per line",
+			list:      func(v *sourceValues) *[]string { return &v.prompts },
+			show:      func(c ConfigData) string { return showList(c.PromptFlags) },
+			applyList: setList(func(c *ConfigData) *[]string { return &c.PromptFlags })},
+		{key: "prompt_file", short: "pf", path: true, arg: "PATH",
+			usage: "read prompt from file",
+			text:  func(v *sourceValues) *string { return &v.promptFile },
+			show:  func(c ConfigData) string { return showList(c.PromptPaths) },
+			apply: func(c *ConfigData, value, _ string) error {
+				c.PromptPaths = []string{value}
+				return nil
+			}},
+		{key: "default_prompt", arg: "TEXT",
+			usage: "prompt used when only files are given\n(default: Analyze the following:)",
+			text:  func(v *sourceValues) *string { return &v.defaultPrompt },
+			show:  func(c ConfigData) string { return showText(c.DefaultPrompt) },
+			apply: setText(func(c *ConfigData) *string { return &c.DefaultPrompt })},
+		{key: "stdin_file", short: "F", kind: kindBool,
+			usage: "treat stdin as file content",
+			text:  func(v *sourceValues) *string { return &v.stdinFile },
+			show:  func(c ConfigData) string { return strconv.FormatBool(c.StdinAsFile) },
+			apply: setBool(func(c *ConfigData) *bool { return &c.StdinAsFile })},
+	}},
+	{title: "System", options: []option{
+		{key: "system", short: "s", arg: "TEXT",
+			usage: "system prompt text",
+			text:  func(v *sourceValues) *string { return &v.system },
+			show:  func(c ConfigData) string { return showText(c.SystemPrompt) }},
+		{key: "system_file", short: "sf", path: true, arg: "PATH",
+			usage: "read system prompt from file\n(--system wins if both are given)",
+			text:  func(v *sourceValues) *string { return &v.systemFile }},
+	}},
+	{title: "API", options: []option{
+		{key: "protocol", short: "l", arg: "PROTO",
+			usage: "API protocol (default: openai)\n{protocols}",
+			text:  func(v *sourceValues) *string { return &v.protocol },
+			show:  func(c ConfigData) string { return string(c.Protocol) },
+			apply: func(c *ConfigData, value, _ string) error {
+				c.Protocol = APIProtocol(value)
+				return nil
+			}},
+		{key: "url", short: "u", arg: "URL",
+			usage: "endpoint (default: https://api.ppq.ai/chat/completions)\n" +
+				"for gemini, the API base URL; the model path is appended\n" +
+				"unix:///path.sock:/api/chat to use a Unix socket",
+			envUsage: "endpoint URL",
+			text:     func(v *sourceValues) *string { return &v.url },
+			show:     func(c ConfigData) string { return c.URL },
+			apply:    setText(func(c *ConfigData) *string { return &c.URL })},
+		{key: "endpoint", arg: "URL",
+			usage:    "azure resource endpoint (replaces --url)",
+			envUsage: "azure resource endpoint",
+			text:     func(v *sourceValues) *string { return &v.endpoint },
+			show:     func(c ConfigData) string { return c.Endpoint },
+			apply:    setText(func(c *ConfigData) *string { return &c.Endpoint })},
+		{key: "deployment", arg: "NAME",
+			usage:    "azure deployment (replaces --model); fallbacks\nname other deployments",
+			envUsage: "azure deployment",
+			text:     func(v *sourceValues) *string { return &v.deployment },
+			show:     func(c ConfigData) string { return c.Deployment },
+			apply:    setText(func(c *ConfigData) *string { return &c.Deployment })},
+		{key: "api_version", arg: "VERSION",
+			usage: "azure API version (default: 2024-10-21)",
+			text:  func(v *sourceValues) *string { return &v.apiVersion },
+			show:  func(c ConfigData) string { return c.APIVersion },
+			apply: setText(func(c *ConfigData) *string { return &c.APIVersion })},
+		{key: "key", short: "k", env: "AICLI_API_KEY", arg: "KEY",
+			usage: "API key",
+			text:  func(v *sourceValues) *string { return &v.key },
+			show:  func(c ConfigData) string { return redact(c.APIKey) }},
+		{key: "key_file", short: "kf", env: "AICLI_API_KEY_FILE", path: true, arg: "PATH",
+			usage: "read API key from file",
+			text:  func(v *sourceValues) *string { return &v.keyFile }},
+		{key: "key_cmd", env: "AICLI_API_KEY_CMD", arg: "CMD",
+			usage:    "run CMD with the shell and read the API key from\nthe first line of its output (30s timeout)",
+			envUsage: "command printing the API key",
+			text:     func(v *sourceValues) *string { return &v.keyCmd }},
+		{key: "auth", arg: "MODE",
+			usage:    "how the key is sent: bearer, header:NAME,\nquery:NAME or none (default: protocol's scheme)",
+			envUsage: "how the key is sent, as for --auth",
+			text:     func(v *sourceValues) *string { return &v.auth },
+			show:     showAuth,
+			apply:    applyAuth},
+		{key: "headers", flag: "header", kind: kindList, listSep: "\n", arg: `"NAME: VALUE"`,
+			usage:     "extra request header (repeatable)",
+			envUsage:  `extra request headers, one "NAME: VALUE" per line`,
+			list:      func(v *sourceValues) *[]string { return &v.headers },
+			show:      showHeaders,
+			applyList: applyHeaders},
+	}},
+	{title: "Models", note: "Models may be PROVIDER:NAME to use a provider defined in the config file.", options: []option{
+		{key: "model", short: "m", arg: "NAME",
+			usage: "primary model (default: gpt-4o-mini)",
+			text:  func(v *sourceValues) *string { return &v.model },
+			show:  func(c ConfigData) string { return c.Model },
+			apply: setText(func(c *ConfigData) *string { return &c.Model })},
+		{key: "fallback", short: "b", kind: kindList, arg: "NAMES",
+			usage:    "comma-separated fallback list, repeatable\n(default: gpt-4.1-mini)",
+			envUsage: "comma-separated fallback list",
+			list:     func(v *sourceValues) *[]string { return &v.fallback },
+			show:     func(c ConfigData) string { return strings.Join(c.FallbackModels, ",") },
+			applyList: func(c *ConfigData, values []string, _ string) error {
+				// Empty entries are dropped; a list of none leaves lower layers' fallbacks
+				var models []string
+				for _, value := range values {
+					for _, model := range strings.Split(value, ",") {
+						if model = strings.TrimSpace(model); model != "" {
+							models = append(models, model)
+						}
+					}
+				}
+				if len(models) > 0 {
+					c.FallbackModels = models
+				}
+				return nil
+			}},
+		{key: "fallback_on", arg: "CLASSES",
+			usage: "error classes that trigger fallback\n{classes}\n(default: all except auth; auth errors still fall\nback to a model on another provider)",
+			text:  func(v *sourceValues) *string { return &v.fallbackOn },
+			show:  showErrorClasses,
+			apply: func(c *ConfigData, value, _ string) error {
+				c.FallbackOn = parseErrorClasses(value)
+				return nil
+			}},
+	}},
+	{title: "Generation", options: []option{
+		{key: "temperature", arg: "N",
+			usage: "sampling temperature, 0 to 2",
+			text:  func(v *sourceValues) *string { return &v.temperature },
+			show:  func(c ConfigData) string { return showFloat(c.Temperature) },
+			apply: setFloat(func(c *ConfigData) **float64 { return &c.Temperature })},
+		{key: "top_p", arg: "N",
+			usage: "nucleus sampling probability, 0 to 1",
+			text:  func(v *sourceValues) *string { return &v.topP },
+			show:  func(c ConfigData) string { return showFloat(c.TopP) },
+			apply: setFloat(func(c *ConfigData) **float64 { return &c.TopP })},
+		{key: "max_tokens", arg: "N",
+			usage: "maximum tokens to generate",
+			text:  func(v *sourceValues) *string { return &v.maxTokens },
+			show:  func(c ConfigData) string { return showInt(c.MaxTokens) },
+			apply: func(c *ConfigData, value, name string) error {
+				n, err := strconv.Atoi(value)
+				if err != nil {
+					return fmt.Errorf("invalid %s: must be an integer, got: %s", name, value)
+				}
+				c.MaxTokens = &n
+				return nil
+			}},
+		{key: "seed", arg: "N",
+			usage: "sampling seed, where supported",
+			text:  func(v *sourceValues) *string { return &v.seed },
+			show:  func(c ConfigData) string { return showInt64(c.Seed) },
+			apply: func(c *ConfigData, value, name string) error {
+				n, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid %s: must be an integer, got: %s", name, value)
+				}
+				c.Seed = &n
+				return nil
+			}},
+		{key: "stop", kind: kindList, arg: "TEXT",
+			usage:     "stop sequence (repeatable)",
+			envUsage:  "comma-separated stop sequences",
+			list:      func(v *sourceValues) *[]string { return &v.stop },
+			show:      func(c ConfigData) string { return showList(c.Stop) },
+			applyList: setList(func(c *ConfigData) *[]string { return &c.Stop })},
+		{key: "extra_body", flag: "param", env: "AICLI_PARAMS", kind: kindList, listSep: "\n", noFile: true, arg: "KEY=VALUE",
+			usage: "extra request body field (repeatable); VALUE is\n" +
+				"parsed as JSON, dotted keys nest (options.num_ctx=8192);\n" +
+				"the config file uses an extra_body map instead",
+			envUsage: "extra request body fields, one KEY=VALUE per line",
+			list:     func(v *sourceValues) *[]string { return &v.params },
+			show:     showExtraBody,
+			applyList: func(c *ConfigData, values []string, name string) error {
+				body, err := applyParams(c.ExtraBody, values, name)
+				c.ExtraBody = body
+				return err
+			}},
+	}},
+	{title: "Retry", options: []option{
+		{key: "retries", arg: "N",
+			usage: "retries per model for transient errors (default: 0)",
+			text:  func(v *sourceValues) *string { return &v.retries },
+			show:  func(c ConfigData) string { return strconv.Itoa(c.Retries) },
+			apply: func(c *ConfigData, value, name string) error {
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 0 {
+					return fmt.Errorf("invalid %s: must be a non-negative integer, got: %s", name, value)
+				}
+				c.Retries = n
+				return nil
+			}},
+		{key: "retry_max_wait", arg: "DUR",
+			usage: "longest wait between retries (default: 30s)",
+			text:  func(v *sourceValues) *string { return &v.retryMaxWait },
+			show:  func(c ConfigData) string { return c.RetryMaxWait.String() },
+			apply: setDuration(func(c *ConfigData) *time.Duration { return &c.RetryMaxWait }, "30s")},
+	}},
+	{title: "Transport", options: []option{
+		{key: "proxy", arg: "URL",
+			usage: "proxy for API requests (default: HTTPS_PROXY/HTTP_PROXY)",
+			text:  func(v *sourceValues) *string { return &v.proxy },
+			show:  func(c ConfigData) string { return c.Proxy },
+			apply: setText(func(c *ConfigData) *string { return &c.Proxy })},
+		{key: "ca_cert", path: true, arg: "PATH",
+			usage: "extra CA bundle (PEM) to trust",
+			text:  func(v *sourceValues) *string { return &v.caCert },
+			show:  func(c ConfigData) string { return c.CACert },
+			apply: setText(func(c *ConfigData) *string { return &c.CACert })},
+		{key: "client_cert", path: true, arg: "PATH",
+			usage: "client certificate (PEM) for mTLS",
+			text:  func(v *sourceValues) *string { return &v.clientCert },
+			show:  func(c ConfigData) string { return c.ClientCert },
+			apply: setText(func(c *ConfigData) *string { return &c.ClientCert })},
+		{key: "client_key", path: true, arg: "PATH",
+			usage: "client private key (PEM) for mTLS",
+			text:  func(v *sourceValues) *string { return &v.clientKey },
+			show:  func(c ConfigData) string { return c.ClientKey },
+			apply: setText(func(c *ConfigData) *string { return &c.ClientKey })},
+		{key: "connect_timeout", arg: "DUR",
+			usage: "connection timeout (default: 30s)",
+			text:  func(v *sourceValues) *string { return &v.connectTimeout },
+			show:  func(c ConfigData) string { return c.ConnectTimeout.String() },
+			apply: setDuration(func(c *ConfigData) *time.Duration { return &c.ConnectTimeout }, "30s")},
+		{key: "timeout", arg: "DUR",
+			usage:    "overall request timeout, 0 for none (default: 5m);\nwith --stream, the longest wait for the next chunk",
+			envUsage: "overall request timeout, or stream idle timeout (default: 5m)",
+			text:     func(v *sourceValues) *string { return &v.timeout },
+			show:     func(c ConfigData) string { return c.Timeout.String() },
+			apply:    setDuration(func(c *ConfigData) *time.Duration { return &c.Timeout }, "5m")},
+	}},
+	{title: "Output", options: []option{
+		{key: "output", short: "o", path: true, arg: "PATH",
+			usage: "write to file (mode 0644) instead of stdout",
+			text:  func(v *sourceValues) *string { return &v.output },
+			show:  func(c ConfigData) string { return c.Output },
+			apply: setText(func(c *ConfigData) *string { return &c.Output })},
+		{key: "stream", kind: kindBool,
+			usage: "write the response as it is generated",
+			text:  func(v *sourceValues) *string { return &v.stream },
+			show:  func(c ConfigData) string { return strconv.FormatBool(c.Stream) },
+			apply: setBool(func(c *ConfigData) *bool { return &c.Stream })},
+		{key: "quiet", short: "q", kind: kindBool,
+			usage: "suppress progress messages",
+			text:  func(v *sourceValues) *string { return &v.quiet },
+			show:  func(c ConfigData) string { return strconv.FormatBool(c.Quiet) },
+			apply: setBool(func(c *ConfigData) *bool { return &c.Quiet })},
+		{key: "verbose", short: "v", kind: kindBool,
+			usage: "log debug information to stderr",
+			text:  func(v *sourceValues) *string { return &v.verbose },
+			show:  func(c ConfigData) string { return strconv.FormatBool(c.Verbose) },
+			apply: setBool(func(c *ConfigData) *bool { return &c.Verbose })},
+	}},
+	{title: "Config", options: []option{
+		{key: "config", short: "c", env: "AICLI_CONFIG_FILE", noFile: true, path: true, arg: "PATH",
+			usage:    "YAML config file, replacing discovery",
+			envUsage: "path to config file",
+			text:     func(v *sourceValues) *string { return &v.config }},
+		{key: "profile", noFile: true, arg: "NAME",
+			usage: "config file profile (default: default_profile)",
+			text:  func(v *sourceValues) *string { return &v.profile },
+			show:  func(c ConfigData) string { return c.Profile }},
+		{key: "env_file", noFile: true, path: true, arg: "PATH",
+			usage:    "dotenv file of AICLI_* variables; the process\nenvironment wins",
+			envUsage: "dotenv file of AICLI_* variables",
+			text:     func(v *sourceValues) *string { return &v.envFile },
+			show:     func(c ConfigData) string { return c.EnvFile },
+			apply:    setText(func(c *ConfigData) *string { return &c.EnvFile })},
+		{key: "dotenv", kind: kindBool,
+			usage: "read ./.env when no env file is given",
+			text:  func(v *sourceValues) *string { return &v.dotenv },
+			show:  func(c ConfigData) string { return strconv.FormatBool(c.Dotenv) },
+			apply: setBool(func(c *ConfigData) *bool { return &c.Dotenv })},
+		{key: "strict", kind: kindBool,
+			usage: "fail on unknown config keys, mistyped values\nand malformed URLs instead of warning",
+			text:  func(v *sourceValues) *string { return &v.strict },
+			show:  func(c ConfigData) string { return strconv.FormatBool(c.Strict) },
+			apply: setBool(func(c *ConfigData) *bool { return &c.Strict })},
+	}},
+}
+
+// options is the schema in usage order.
+var options = flattenSections(optionSections)
+
+func flattenSections(sections []optionSection) []option {
+	var all []option
+	for _, s := range sections {
+		all = append(all, s.options...)
+	}
+	return all
+}
+
+// lookupOption returns the option with the given key.
+func lookupOption(key string) option {
+	if o, ok := findOption(key); ok {
+		return o
+	}
+	panic("config: no option " + key)
+}
+
+// findOption returns the option with the given key, if there is one.
+func findOption(key string) (option, bool) {
+	for _, o := range options {
+		if o.key == key {
+			return o, true
+		}
+	}
+	return option{}, false
+}
+
+// isSet reports whether values sets the option.
+func (o option) isSet(values *sourceValues) bool {
+	if o.kind == kindList {
+		return len(*o.list(values)) > 0
+	}
+	return *o.text(values) != ""
+}
+
+// flagName is the long flag, without dashes.
+func (o option) flagName() string {
+	if o.flag != "" {
+		return o.flag
+	}
+	return strings.ReplaceAll(o.key, "_", "-")
+}
+
+// envName is the environment variable.
+func (o option) envName() string {
+	if o.env != "" {
+		return o.env
+	}
+	return "AICLI_" + strings.ToUpper(o.key)
+}
+
+// separator splits a list option's environment variable.
+func (o option) separator() string {
+	if o.listSep != "" {
+		return o.listSep
+	}
+	return ","
+}
+
+// fileOptionName, envOptionName and flagOptionName spell an option as each
+// source does, for error messages. The file layer adds where the key was set
+// when it is known.
+func fileOptionName(file sourceValues) func(option) string {
+	return func(o option) string {
+		if at := file.origins.fileKey(o.key); at.path != "" {
+			return fmt.Sprintf("%s (%s)", o.key, at)
+		}
+		return "config " + o.key
+	}
+}
+
+func envOptionName(o option) string { return o.envName() }
+
+func flagOptionName(o option) string { return "--" + o.flagName() }
+
+// applyOptions parses every option set in values into cfg.
+func applyOptions(cfg *ConfigData, values sourceValues, nameOf func(option) string) error {
+	for _, o := range options {
+		switch {
+		case o.applyList != nil:
+			if list := *o.list(&values); len(list) > 0 {
+				if err := o.applyList(cfg, list, nameOf(o)); err != nil {
+					return err
+				}
+			}
+		case o.apply != nil:
+			if value := *o.text(&values); value != "" {
+				if err := o.apply(cfg, value, nameOf(o)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func setText(field func(*ConfigData) *string) func(*ConfigData, string, string) error {
+	return func(cfg *ConfigData, value, _ string) error {
+		*field(cfg) = value
+		return nil
+	}
+}
+
+func setList(field func(*ConfigData) *[]string) func(*ConfigData, []string, string) error {
+	return func(cfg *ConfigData, values []string, _ string) error {
+		*field(cfg) = values
+		return nil
+	}
+}
+
+func setBool(field func(*ConfigData) *bool) func(*ConfigData, string, string) error {
+	return func(cfg *ConfigData, value, name string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: must be true or false, got: %s", name, value)
+		}
+		*field(cfg) = b
+		return nil
+	}
+}
+
+// setFloat parses a number; ranges are checked later by validateConfig.
+func setFloat(field func(*ConfigData) **float64) func(*ConfigData, string, string) error {
+	return func(cfg *ConfigData, value, name string) error {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s: must be a number, got: %s", name, value)
+		}
+		*field(cfg) = &v
+		return nil
+	}
+}
+
+// setDuration parses a non-negative duration; example appears in errors.
+func setDuration(field func(*ConfigData) *time.Duration, example string) func(*ConfigData, string, string) error {
+	return func(cfg *ConfigData, value, name string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid %s: must be a duration such as %s, got: %s", name, example, value)
+		}
+		*field(cfg) = d
+		return nil
+	}
+}
+
+// applyHeaders adds "Name: value" headers to those set by lower layers.
+func applyHeaders(cfg *ConfigData, headers []string, name string) error {
+	for _, header := range headers {
+		key, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid %s: must be \"Name: value\", got: %s", name, header)
+		}
+		setHeader(cfg, key, value)
+	}
+	return nil
+}
+
+// usageColumn is where descriptions start in the usage text.
+const usageColumn = 27
+
+// optionUsage renders the option sections of the usage text.
+func optionUsage() string {
+	var b strings.Builder
+	for _, s := range optionSections {
+		fmt.Fprintf(&b, "%s:\n", s.title)
+		for _, o := range s.options {
+			name := "--" + o.flagName()
+			if o.short != "" {
+				name = "-" + o.short + ", " + name
+			}
+			if o.arg != "" {
+				name += " " + o.arg
+			}
+			writeUsageEntry(&b, name, o.usage)
+		}
+		if s.note != "" {
+			fmt.Fprintf(&b, "  %s\n", s.note)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// envUsage renders the environment variable list of the usage text.
+func envUsage() string {
+	var b strings.Builder
+	b.WriteString("Environment Variables:\n")
+	for _, o := range options {
+		text := o.envUsage
+		if text == "" {
+			text, _, _ = strings.Cut(o.usage, "\n")
+		}
+		writeUsageEntry(&b, o.envName(), text)
+	}
+	return b.String()
+}
+
+// writeUsageEntry writes name and its description, aligning the description
+// and any continuation lines at usageColumn.
+func writeUsageEntry(b *strings.Builder, name, text string) {
+	lines := strings.Split(text, "\n")
+	pad := usageColumn - 2 - len(name)
+	if pad < 2 {
+		pad = 2
+	}
+	fmt.Fprintf(b, "  %s%s%s\n", name, strings.Repeat(" ", pad), lines[0])
+	for _, line := range lines[1:] {
+		fmt.Fprintf(b, "%s%s\n", strings.Repeat(" ", usageColumn), line)
+	}
+}
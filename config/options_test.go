@@ -0,0 +1,52 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionSchema(t *testing.T) {
+	flags := map[string]bool{"version": true}
+	envs := map[string]bool{}
+	keys := map[string]bool{}
+
+	for _, o := range options {
+		t.Run(o.key, func(t *testing.T) {
+			if o.kind == kindList {
+				assert.NotNil(t, o.list, "list options need a list field")
+				assert.Nil(t, o.apply)
+			} else {
+				assert.NotNil(t, o.text, "text and bool options need a text field")
+				assert.Nil(t, o.applyList)
+			}
+
+			for _, name := range []string{o.flagName(), o.short} {
+				if name != "" {
+					assert.False(t, flags[name], "duplicate flag -%s", name)
+					flags[name] = true
+				}
+			}
+			assert.False(t, envs[o.envName()], "duplicate env var %s", o.envName())
+			envs[o.envName()] = true
+			assert.False(t, keys[o.key], "duplicate key %s", o.key)
+			keys[o.key] = true
+
+			assert.Contains(t, UsageText, "--"+o.flagName())
+			assert.Contains(t, UsageText, o.envName())
+		})
+	}
+}
+
+func TestWriteUsageEntry(t *testing.T) {
+	var b strings.Builder
+	writeUsageEntry(&b, "-pf, --prompt-file PATH", "read prompt\nfrom file")
+	writeUsageEntry(&b, "--a-very-long-option-name VALUE", "text")
+
+	assert.Equal(t,
+		"  -pf, --prompt-file PATH  read prompt\n"+
+			"                           from file\n"+
+			"  --a-very-long-option-name VALUE  text\n",
+		b.String())
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// originKind is the layer a setting came from.
+type originKind string
+
+const (
+	originDefault originKind = "default"
+	originFile    originKind = "file"
+	originEnv     originKind = "env"
+	originEnvFile originKind = "env file"
+	originFlag    originKind = "flag"
+)
+
+// origin records where a setting's value came from.
+type origin struct {
+	kind originKind
+	name string // flag, environment variable or config file key
+	path string // config or env file
+	line int
+}
+
+func (o origin) String() string {
+	switch o.kind {
+	case originFlag:
+		return "flag " + o.name
+	case originEnv:
+		return "env " + o.name
+	case originEnvFile:
+		return fmt.Sprintf("%s:%d %s", o.path, o.line, o.name)
+	case originFile:
+		if o.path == "" {
+			return "config " + o.name
+		}
+		return fmt.Sprintf("%s:%d", o.path, o.line)
+	}
+	return string(originDefault)
+}
+
+// origins maps settings to where they came from. mergeSources keys it by
+// option key; the config file layer keys it by dotted file key, such as
+// profiles.work.model. Settings left at their defaults are absent.
+type origins map[string]origin
+
+// lookup returns the origin of key, or the default origin.
+func (o origins) lookup(key string) origin {
+	if at, ok := o[key]; ok {
+		return at
+	}
+	return origin{kind: originDefault}
+}
+
+// fileKey returns the origin of a config file key, naming just the key when
+// its line is not known.
+func (o origins) fileKey(key string) origin {
+	if at, ok := o[key]; ok {
+		return at
+	}
+	return origin{kind: originFile, name: key}
+}
+
+// fileOrigin, envOrigin and flagOrigin give the origin of an option set in
+// each layer. Environment values read from an env file carry their own.
+func fileOrigin(file sourceValues) func(option) origin {
+	return func(o option) origin { return file.origins.fileKey(o.key) }
+}
+
+func envOrigin(env sourceValues) func(option) origin {
+	return func(o option) origin {
+		if at, ok := env.origins[o.key]; ok {
+			return at
+		}
+		return origin{kind: originEnv, name: o.envName()}
+	}
+}
+
+func flagOrigin(o option) origin { return origin{kind: originFlag, name: "--" + o.flagName()} }
+
+// recordOrigins notes the origin of every option set in values, replacing
+// those of lower layers.
+func recordOrigins(dst origins, values sourceValues, originOf func(option) origin) {
+	for _, o := range options {
+		if o.isSet(&values) {
+			dst[o.key] = originOf(o)
+		}
+	}
+}
+
+// keyLines records the line of every mapping key under node, by dotted path.
+func keyLines(node *yaml.Node, prefix string, lines map[string]int) {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		path := prefix + key.Value
+		lines[path] = key.Line
+		keyLines(value, path+".", lines)
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// expandPath replaces a leading ~ with the home directory, and $VAR and
+// ${VAR} with the value of the environment variable.
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || (len(path) > 1 && path[0] == '~' && os.IsPathSeparator(path[1])) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + path[1:]
+		}
+	}
+	return path
+}
+
+// resolvePath expands path and joins it to dir if it is still relative. An
+// empty dir leaves relative paths relative to the working directory.
+func resolvePath(path, dir string) string {
+	path = expandPath(path)
+	if dir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return path
+}
+
+// expandPaths resolves every path option set in values. dirOf returns the
+// directory relative paths of an option are resolved against; nil means
+// the working directory.
+func expandPaths(values *sourceValues, dirOf func(option) string) {
+	for _, o := range options {
+		if !o.path {
+			continue
+		}
+		dir := ""
+		if dirOf != nil && o.isSet(values) {
+			dir = dirOf(o)
+		}
+		if o.kind == kindList {
+			for i, path := range *o.list(values) {
+				(*o.list(values))[i] = resolvePath(path, dir)
+			}
+		} else if path := *o.text(values); path != "" {
+			*o.text(values) = resolvePath(path, dir)
+		}
+	}
+}
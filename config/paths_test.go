@@ -0,0 +1,56 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResolvePath(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	t.Setenv("PROMPTS", "/srv/prompts")
+	t.Setenv("AICLI_TEST_EMPTY", "")
+
+	tests := []struct {
+		name string
+		path string
+		dir  string
+		want string
+	}{
+		{name: "home", path: "~", want: "/home/me"},
+		{name: "home prefix", path: "~/.aicli_key", want: "/home/me/.aicli_key"},
+		{name: "other user untouched", path: "~bob/key", want: "~bob/key"},
+		{name: "tilde inside untouched", path: "notes/~draft", want: "notes/~draft"},
+		{name: "variable", path: "$PROMPTS/system.txt", want: "/srv/prompts/system.txt"},
+		{name: "braced variable", path: "${PROMPTS}_old/system.txt", want: "/srv/prompts_old/system.txt"},
+		{name: "unset variable", path: "$AICLI_TEST_EMPTY/key", want: "/key"},
+		{name: "relative kept without dir", path: "main.go", want: "main.go"},
+		{name: "relative joined to dir", path: "prompts/review.txt", dir: "/repo", want: "/repo/prompts/review.txt"},
+		{name: "absolute ignores dir", path: "/etc/key", dir: "/repo", want: "/etc/key"},
+		{name: "expanded ignores dir", path: "~/key", dir: "/repo", want: "/home/me/key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resolvePath(tt.path, tt.dir))
+		})
+	}
+}
+
+func TestExpandPathsFlagsAndEnv(t *testing.T) {
+	for _, o := range options {
+		t.Setenv(o.envName(), "")
+	}
+	t.Setenv("HOME", "/home/me")
+	t.Setenv("AICLI_API_KEY_FILE", "~/.aicli_key")
+	t.Setenv("AICLI_FILES", "~/a.go,b.go")
+
+	env := loadEnvironment(nil)
+	assert.Equal(t, "/home/me/.aicli_key", env.keyFile)
+	assert.Equal(t, []string{"/home/me/a.go", "b.go"}, env.files)
+
+	flags, err := parseFlags([]string{"-o", "$HOME/out.md", "--system-file=~/system.txt", "-m", "~model"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/home/me/out.md", flags.output)
+	assert.Equal(t, "/home/me/system.txt", flags.systemFile)
+	assert.Equal(t, "~model", flags.model)
+}
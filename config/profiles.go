@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// exclusiveKeys maps each file key to the others that set the same option.
+// A profile that sets one clears the rest, so system_file or key_file in a
+// profile is not shadowed by a top-level system or key.
+var exclusiveKeys = map[string][]string{
+	"system":      {"system_file"},
+	"system_file": {"system"},
+	"key":         {"key_file", "key_cmd"},
+	"key_file":    {"key", "key_cmd"},
+	"key_cmd":     {"key", "key_file"},
+}
+
+// clearExclusive deletes from dst the keys that set the same option as a key
+// of values, unless values sets them too.
+func clearExclusive(dst, values map[string]interface{}) {
+	for key := range values {
+		for _, other := range exclusiveKeys[key] {
+			if _, ok := values[other]; !ok {
+				delete(dst, other)
+			}
+		}
+	}
+}
+
+// applyProfile replaces the top-level keys of raw with those of the named
+// profile. An empty name selects default_profile, if set. It returns the
+// name of the profile applied, or "" when none was.
+func applyProfile(raw map[string]interface{}, name string) (string, error) {
+	profiles, _ := raw["profiles"].(map[string]interface{})
+	delete(raw, "profiles")
+
+	if name == "" {
+		name, _ = raw["default_profile"].(string)
+	}
+	delete(raw, "default_profile")
+	if name == "" {
+		return "", nil
+	}
+
+	values, ok := profiles[name]
+	if !ok {
+		return "", fmt.Errorf("unknown profile %q: defined profiles are %s", name, profileList(profiles))
+	}
+	profile, ok := values.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("profiles.%s: must be a map", name)
+	}
+
+	for key := range profile {
+		if key == "profiles" || key == "default_profile" {
+			return "", fmt.Errorf("profiles.%s: %s cannot be set in a profile", name, key)
+		}
+	}
+	clearExclusive(raw, profile)
+	for key, value := range profile {
+		raw[key] = value
+	}
+	return name, nil
+}
+
+// profileList formats the profile names for error messages.
+func profileList(profiles map[string]interface{}) string {
+	if len(profiles) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
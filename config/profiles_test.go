@@ -0,0 +1,117 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoadConfigFileProfiles(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		profile     string
+		want        sourceValues
+		errContains string
+	}{
+		{
+			name:    "default profile",
+			path:    "testdata/profiles.yaml",
+			profile: "",
+			want: sourceValues{
+				generationValues: generationValues{temperature: "0.2"},
+				url:              "https://api.ppq.ai/chat/completions",
+				model:            "gpt-4o-mini",
+				systemFile:       "testdata/system.txt",
+				profile:          "code",
+			},
+		},
+		{
+			name:    "named profile overrides top-level keys",
+			path:    "testdata/profiles.yaml",
+			profile: "creative",
+			want: sourceValues{
+				generationValues: generationValues{temperature: "1.2"},
+				url:              "https://api.ppq.ai/chat/completions",
+				model:            "gpt-4o",
+				system:           "You are a creative writer",
+				profile:          "creative",
+			},
+		},
+		{
+			name:    "profile switches endpoint",
+			path:    "testdata/profiles.yaml",
+			profile: "local",
+			want: sourceValues{
+				generationValues: generationValues{temperature: "0.5"},
+				protocol:         "ollama-chat",
+				url:              "http://localhost:11434/api/chat",
+				auth:             "none",
+				model:            "llama3",
+				system:           "You are a helpful assistant",
+				profile:          "local",
+			},
+		},
+		{
+			name:    "profile key_file replaces top-level key and key_file",
+			path:    "testdata/profile_keys.yaml",
+			profile: "file",
+			want: sourceValues{
+				keyFile: "testdata/api.key",
+				profile: "file",
+			},
+		},
+		{
+			name:    "profile key_cmd replaces top-level key and key_file",
+			path:    "testdata/profile_keys.yaml",
+			profile: "cmd",
+			want: sourceValues{
+				keyCmd:  "echo sk-from-cmd",
+				profile: "cmd",
+			},
+		},
+		{
+			name:    "no profiles defined",
+			path:    "testdata/partial.yaml",
+			profile: "",
+			want: sourceValues{
+				model:    "gpt-4",
+				fallback: []string{"gpt-3.5-turbo"},
+			},
+		},
+		{
+			name:        "unknown profile",
+			path:        "testdata/profiles.yaml",
+			profile:     "missing",
+			errContains: `unknown profile "missing": defined profiles are broken, code, creative, local`,
+		},
+		{
+			name:        "profile not a map",
+			path:        "testdata/profiles.yaml",
+			profile:     "broken",
+			errContains: "profiles.broken: must be a map",
+		},
+		{
+			name:        "profile without config file",
+			profile:     "code",
+			errContains: "no config file was found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			if tt.path != "" {
+				paths = []string{tt.path}
+			}
+			got, err := loadConfigFiles(paths, tt.profile)
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			assert.NoError(t, err)
+			got.origins = nil  // see TestLoadConfigFileOrigins
+			got.problems = nil // profiles.broken; see TestCheckConfigFile
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
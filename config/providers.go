@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProviderConfig is a named endpoint selected by model entries of the form
+// "name:model", so a fallback chain can span several APIs.
+type ProviderConfig struct {
+	Protocol   APIProtocol
+	URL        string
+	APIKey     string
+	Auth       AuthMode
+	AuthName   string
+	Headers    map[string]string
+	APIVersion string
+}
+
+// providerValues holds one providers: entry as read from the config file.
+type providerValues struct {
+	protocol   string
+	url        string
+	key        string
+	keyFile    string
+	auth       string
+	apiVersion string
+	headers    map[string]string
+}
+
+// ForModel returns the configuration for one model entry, the bare model
+// name, and the provider name. An entry whose prefix names a configured
+// provider uses that provider's protocol, URL, key, auth and headers. Any
+// other entry, including Ollama tags such as llama3:8b, uses c unchanged and
+// an empty provider name.
+func (c ConfigData) ForModel(entry string) (ConfigData, string, string) {
+	name, model, found := strings.Cut(entry, ":")
+	p, ok := c.Providers[name]
+	if !found || !ok {
+		return c, entry, ""
+	}
+
+	c.Protocol = p.Protocol
+	c.URL = p.URL
+	c.APIKey = p.APIKey
+	c.Auth = p.Auth
+	c.AuthName = p.AuthName
+	c.Headers = p.Headers
+	c.APIVersion = p.APIVersion
+	return c, model, name
+}
+
+// ModelEntries returns the model and its fallbacks, in the order they are
+// tried.
+func (c ConfigData) ModelEntries() []string {
+	return append([]string{c.Model}, c.FallbackModels...)
+}
+
+// buildProviders resolves the providers: section. Providers inherit nothing
+// from the top-level settings, so a key is never sent to another host by
+// accident; an unset protocol means openai. Key files are read only for
+// providers some model entry selects, so a stale unused entry does no harm.
+func buildProviders(values map[string]providerValues, entries []string, at origins) (map[string]ProviderConfig, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	used := map[string]bool{}
+	for _, entry := range entries {
+		if name, _, found := strings.Cut(entry, ":"); found {
+			used[name] = true
+		}
+	}
+
+	providers := make(map[string]ProviderConfig, len(values))
+	for name, v := range values {
+		p := ProviderConfig{
+			Protocol:   ProtocolOpenAI,
+			URL:        v.url,
+			APIKey:     v.key,
+			APIVersion: v.apiVersion,
+		}
+		if v.protocol != "" {
+			p.Protocol = APIProtocol(v.protocol)
+		}
+		if p.APIKey == "" && v.keyFile != "" && used[name] {
+			key, err := readKeyFile(v.keyFile, at.fileKey("providers."+name+".key_file"))
+			if err != nil {
+				return nil, err
+			}
+			p.APIKey = key
+		}
+
+		var auth ConfigData
+		if err := applyAuth(&auth, v.auth, "providers."+name+".auth"); err != nil {
+			return nil, err
+		}
+		p.Auth, p.AuthName = auth.Auth, auth.AuthName
+		for header, value := range v.headers {
+			setHeader(&auth, header, value)
+		}
+		p.Headers = auth.Headers
+
+		providers[name] = p
+	}
+	return providers, nil
+}
+
+// validateProvider applies the top-level checks to one named provider.
+func validateProvider(name string, p ProviderConfig) error {
+	if p.URL == "" {
+		return fmt.Errorf("provider %s: url required", name)
+	}
+	if p.APIKey == "" && p.Auth != AuthNone {
+		return fmt.Errorf("provider %s: key or key_file required, or set auth: none", name)
+	}
+	if !isProtocol(p.Protocol) {
+		return fmt.Errorf("provider %s: invalid protocol: must be one of %s, got: %s", name, protocolList(), p.Protocol)
+	}
+	return nil
+}
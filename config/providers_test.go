@@ -0,0 +1,79 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestForModel(t *testing.T) {
+	cfg := ConfigData{
+		Protocol: ProtocolOpenAI,
+		URL:      "https://api.ppq.ai/chat/completions",
+		APIKey:   "sk-default",
+		Headers:  map[string]string{"X-Team": "a"},
+		Providers: map[string]ProviderConfig{
+			"ollama": {
+				Protocol: ProtocolOllamaChat,
+				URL:      "http://localhost:11434/api/chat",
+				Auth:     AuthNone,
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		entry        string
+		wantModel    string
+		wantProvider string
+		wantProtocol APIProtocol
+		wantURL      string
+		wantKey      string
+	}{
+		{
+			name:         "bare model uses top-level settings",
+			entry:        "gpt-4o",
+			wantModel:    "gpt-4o",
+			wantProtocol: ProtocolOpenAI,
+			wantURL:      "https://api.ppq.ai/chat/completions",
+			wantKey:      "sk-default",
+		},
+		{
+			name:         "named provider",
+			entry:        "ollama:llama3",
+			wantModel:    "llama3",
+			wantProvider: "ollama",
+			wantProtocol: ProtocolOllamaChat,
+			wantURL:      "http://localhost:11434/api/chat",
+		},
+		{
+			name:         "tag after provider is kept",
+			entry:        "ollama:llama3:8b",
+			wantModel:    "llama3:8b",
+			wantProvider: "ollama",
+			wantProtocol: ProtocolOllamaChat,
+			wantURL:      "http://localhost:11434/api/chat",
+		},
+		{
+			name:         "unknown prefix is part of the model",
+			entry:        "llama3:8b",
+			wantModel:    "llama3:8b",
+			wantProtocol: ProtocolOpenAI,
+			wantURL:      "https://api.ppq.ai/chat/completions",
+			wantKey:      "sk-default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, model, provider := cfg.ForModel(tt.entry)
+			assert.Equal(t, tt.wantModel, model)
+			assert.Equal(t, tt.wantProvider, provider)
+			assert.Equal(t, tt.wantProtocol, got.Protocol)
+			assert.Equal(t, tt.wantURL, got.URL)
+			assert.Equal(t, tt.wantKey, got.APIKey)
+			if provider != "" {
+				assert.Nil(t, got.Headers)
+			}
+		})
+	}
+}
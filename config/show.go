@@ -0,0 +1,197 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// IsConfigCommand checks if args start with the config subcommand
+func IsConfigCommand(args []string) bool {
+	return len(args) > 0 && args[0] == "config"
+}
+
+// RunConfigCommand runs "config show" or "config path". args follow
+// "config" and may include any other option, which is resolved as it would
+// be for a request.
+func RunConfigCommand(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("config: expected show or path")
+	}
+	switch args[0] {
+	case "show":
+		return showConfig(args[1:], w)
+	case "path":
+		return showConfigPaths(args[1:], w)
+	}
+	return fmt.Errorf("config: unknown command %q: expected show or path", args[0])
+}
+
+// showConfig prints every setting with its merged value and origin. The
+// settings are printed even if they fail validation, which is then returned.
+func showConfig(args []string, w io.Writer) error {
+	r, err := resolveConfig(args)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, o := range options {
+		if o.show != nil {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", o.key, o.show(r.cfg), r.origins.lookup(o.key))
+		}
+	}
+	names := make([]string, 0, len(r.cfg.Providers))
+	for name := range r.cfg.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := r.cfg.Providers[name]
+		key := "providers." + name
+		fmt.Fprintf(tw, "%s\t%s %s\t%s\n", key, p.Protocol, p.URL, r.origins.lookup(key))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if err := reportProblems(r.cfg, r.problems); err != nil {
+		return err
+	}
+	return validateConfig(r.cfg)
+}
+
+// showConfigPaths prints the config files searched, lowest precedence
+// first, and whether each was found.
+func showConfigPaths(args []string, w io.Writer) error {
+	flags, err := parseFlags(args)
+	if err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	env, _, err := loadEnvLayer(flags)
+	if err != nil {
+		return err
+	}
+	if path := explicitConfig(flags, env); path != "" {
+		source := "--config"
+		if flags.config == "" {
+			source = "AICLI_CONFIG_FILE"
+		}
+		fmt.Fprintf(w, "%s  (%s; discovery skipped)\n", path, source)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, path := range configSearchPaths() {
+		status := "not found"
+		if isFile(path) {
+			status = "loaded"
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", path, status)
+	}
+	return tw.Flush()
+}
+
+// redact hides all but the last four characters of a secret, and all of a
+// short one.
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) < 12 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
+
+// showText quotes text that would not read clearly in a table cell.
+func showText(s string) string {
+	if strings.ContainsAny(s, "\n\t") || strings.TrimSpace(s) != s {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func showList(values []string) string {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = showText(v)
+	}
+	return strings.Join(items, ", ")
+}
+
+func showFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'g', -1, 64)
+}
+
+func showInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+func showInt64(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
+
+func showAuth(cfg ConfigData) string {
+	if cfg.AuthName != "" {
+		return string(cfg.Auth) + ":" + cfg.AuthName
+	}
+	return string(cfg.Auth)
+}
+
+// showHeaders lists headers by name, redacting those that carry credentials.
+func showHeaders(cfg ConfigData) string {
+	headers := make([]string, 0, len(cfg.Headers))
+	for name, value := range cfg.Headers {
+		if sensitiveHeader(name) {
+			value = redact(value)
+		}
+		headers = append(headers, name+": "+value)
+	}
+	sort.Strings(headers)
+	return showList(headers)
+}
+
+// sensitiveHeader guesses from its name whether a header carries a secret.
+func sensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range []string{"authorization", "cookie", "key", "token", "secret"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func showErrorClasses(cfg ConfigData) string {
+	names := make([]string, len(cfg.FallbackOn))
+	for i, c := range cfg.FallbackOn {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ",")
+}
+
+func showExtraBody(cfg ConfigData) string {
+	if len(cfg.ExtraBody) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(cfg.ExtraBody)
+	if err != nil {
+		return fmt.Sprint(cfg.ExtraBody)
+	}
+	return string(data)
+}
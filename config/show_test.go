@@ -0,0 +1,71 @@
+package config
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestRunConfigCommand(t *testing.T) {
+	_, _, project := isolateDiscovery(t)
+	for _, o := range options {
+		t.Setenv(o.envName(), "")
+	}
+	path := filepath.Join(project, ".aicli.yaml")
+	writeFile(t, path, `model: file-model
+key: sk-file-0123456789
+headers:
+  X-Api-Key: secret-header-value
+  X-Team: search
+providers:
+  local:
+    protocol: ollama-chat
+    url: http://localhost:11434/api/chat
+    auth: none
+dotenv: true
+`)
+	writeFile(t, filepath.Join(project, ".env"), "# project settings\nAICLI_TIMEOUT=2m\n")
+	t.Setenv("AICLI_RETRIES", "2")
+
+	var out bytes.Buffer
+	err := RunConfigCommand([]string{"show", "-m", "flag-model"}, &out)
+	assert.NoError(t, err)
+
+	rows := map[string]string{
+		"model":           `flag-model\s+flag --model`,
+		"key":             `\*\*\*\*6789\s+` + regexp.QuoteMeta(path) + `:2`,
+		"headers":         `X-Api-Key: \*\*\*\*alue, X-Team: search\s+` + regexp.QuoteMeta(path) + `:3`,
+		"retries":         `2\s+env AICLI_RETRIES`,
+		"protocol":        `openai\s+default`,
+		"timeout":         `2m0s\s+` + regexp.QuoteMeta(filepath.Join(project, ".env")) + `:2 AICLI_TIMEOUT`,
+		"env_file":        regexp.QuoteMeta(filepath.Join(project, ".env")) + `\s+` + regexp.QuoteMeta(path) + `:11`,
+		"providers.local": `ollama-chat http://localhost:11434/api/chat\s+` + regexp.QuoteMeta(path) + `:7`,
+	}
+	for key, row := range rows {
+		assert.Regexp(t, "(?m)^"+regexp.QuoteMeta(key)+`\s+`+row+"$", out.String())
+	}
+	assert.NotContains(t, out.String(), "sk-file")
+	assert.NotContains(t, out.String(), "secret-header")
+
+	out.Reset()
+	err = RunConfigCommand([]string{"path"}, &out)
+	assert.NoError(t, err)
+	assert.Regexp(t, `(?m)^`+regexp.QuoteMeta(path)+`\s+loaded$`, out.String())
+	assert.Regexp(t, `(?m)config\.yaml\s+not found$`, out.String())
+
+	out.Reset()
+	err = RunConfigCommand([]string{"path", "-c", "testdata/valid.yaml"}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "testdata/valid.yaml  (--config; discovery skipped)\n", out.String())
+
+	err = RunConfigCommand([]string{"list"}, &out)
+	assert.ErrorContains(t, err, `unknown command "list"`)
+}
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, "", redact(""))
+	assert.Equal(t, "****", redact("short"))
+	assert.Equal(t, "****cdef", redact("sk-0123456789abcdef"))
+}
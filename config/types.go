@@ -1,12 +1,58 @@
 package config
 
-type APIProtocol int
+import "time"
 
+// APIProtocol names a provider registered in the provider package.
+type APIProtocol string
+
+// Names of the built-in protocols.
+const (
+	ProtocolOpenAI     APIProtocol = "openai"
+	ProtocolOllama     APIProtocol = "ollama"
+	ProtocolOllamaChat APIProtocol = "ollama-chat"
+	ProtocolAnthropic  APIProtocol = "anthropic"
+	ProtocolGemini     APIProtocol = "gemini"
+	ProtocolResponses  APIProtocol = "responses"
+	ProtocolAzure      APIProtocol = "azure"
+)
+
+// AuthMode selects how the API key is sent.
+type AuthMode string
+
+// Auth modes. AuthDefault uses the protocol's own scheme.
+const (
+	AuthDefault AuthMode = ""
+	AuthBearer  AuthMode = "bearer"
+	AuthHeader  AuthMode = "header"
+	AuthQuery   AuthMode = "query"
+	AuthNone    AuthMode = "none"
+)
+
+// ErrorClass groups request failures for fallback decisions.
+type ErrorClass string
+
+// Error classes reported by the api package.
 const (
-	ProtocolOpenAI APIProtocol = iota
-	ProtocolOllama
+	ErrorAuth          ErrorClass = "auth"
+	ErrorRateLimit     ErrorClass = "rate_limit"
+	ErrorServer        ErrorClass = "server"
+	ErrorContextLength ErrorClass = "context_length"
+	ErrorParse         ErrorClass = "parse"
+	ErrorNetwork       ErrorClass = "network"
+	ErrorRequest       ErrorClass = "request"
 )
 
+// ErrorClasses lists every error class in documentation order.
+var ErrorClasses = []ErrorClass{
+	ErrorAuth,
+	ErrorRateLimit,
+	ErrorServer,
+	ErrorContextLength,
+	ErrorParse,
+	ErrorNetwork,
+	ErrorRequest,
+}
+
 type ConfigData struct {
 	// Input
 	FilePaths   []string
@@ -14,6 +60,10 @@ type ConfigData struct {
 	PromptPaths []string
 	StdinAsFile bool
 
+	// DefaultPrompt precedes files when no prompt is given; empty uses the
+	// built-in prompt
+	DefaultPrompt string
+
 	// System
 	SystemPrompt string
 
@@ -21,51 +71,142 @@ type ConfigData struct {
 	Protocol APIProtocol
 	URL      string
 	APIKey   string
+	Auth     AuthMode
+	AuthName string            // header or query parameter for AuthHeader and AuthQuery
+	Headers  map[string]string // sent with every request, after auth
 
-	// Models
+	// Azure; Endpoint and Deployment replace URL and Model when set
+	Endpoint   string
+	Deployment string
+	APIVersion string
+
+	// Models; entries may be "provider:model" to select a named provider
 	Model          string
 	FallbackModels []string
+	Providers      map[string]ProviderConfig
+	FallbackOn     []ErrorClass
+
+	// Generation; nil and empty values are left to the provider
+	Temperature *float64
+	TopP        *float64
+	MaxTokens   *int
+	Seed        *int64
+	Stop        []string
+
+	// ExtraBody is deep-merged into every request payload
+	ExtraBody map[string]interface{}
+
+	// Retry
+	Retries      int
+	RetryMaxWait time.Duration
+
+	// Transport; a zero timeout means no limit
+	Proxy          string
+	CACert         string
+	ClientCert     string
+	ClientKey      string
+	ConnectTimeout time.Duration
+	Timeout        time.Duration
+
+	// Profile is the config file profile applied, if any
+	Profile string
+
+	// Strict fails on config problems instead of warning
+	Strict bool
+
+	// EnvFile is the dotenv file read, if any; Dotenv reads ./.env when no
+	// env file is named
+	EnvFile string
+	Dotenv  bool
 
 	// Output
 	Output  string
+	Stream  bool
 	Quiet   bool
 	Verbose bool
 }
 
-type flagValues struct {
-	files      []string
-	prompts    []string
-	promptFile string
-	system     string
-	systemFile string
-	key        string
-	keyFile    string
-	protocol   string
-	url        string
-	model      string
-	fallback   string
-	output     string
-	config     string
-	stdinFile  bool
-	quiet      bool
-	verbose    bool
-	version    bool
+// transportValues holds the unparsed HTTP transport settings from one source.
+type transportValues struct {
+	proxy          string
+	caCert         string
+	clientCert     string
+	clientKey      string
+	connectTimeout string
+	timeout        string
 }
 
-type envValues struct {
-	protocol string
-	url      string
-	key      string
-	model    string
-	fallback string
-	system   string
+// generationValues holds the unparsed generation parameters from one source.
+type generationValues struct {
+	temperature string
+	topP        string
+	maxTokens   string
+	seed        string
+	stop        []string
 }
 
-type fileValues struct {
+// sourceValues holds the unparsed settings from one source: command-line
+// flags, the environment, or the merged config files. Every option in the
+// schema has a field here; empty means unset.
+type sourceValues struct {
+	generationValues
+	transportValues
+
+	// Input
+	files         []string
+	prompts       []string
+	promptFile    string
+	defaultPrompt string
+	stdinFile     string
+
+	// System
+	system     string
+	systemFile string
+
+	// API
 	protocol   string
 	url        string
+	key        string
 	keyFile    string
+	keyCmd     string
+	auth       string
+	headers    []string // "Name: value"
+	endpoint   string
+	deployment string
+	apiVersion string
+
+	// Models
 	model      string
-	fallback   string
-	systemFile string
+	fallback   []string
+	fallbackOn string
+
+	// Extra request body; extraBody comes only from the config file
+	params    []string
+	extraBody map[string]interface{}
+
+	// Retry
+	retries      string
+	retryMaxWait string
+
+	// Output
+	output  string
+	stream  string
+	quiet   string
+	verbose string
+
+	// Config; for the config file layer, profile is the profile applied
+	config    string
+	profile   string
+	strict    string
+	envFile   string
+	dotenv    string
+	providers map[string]providerValues
+
+	// origins locates config file keys, and problems lists unknown keys
+	// and mistyped values; both are nil for other layers
+	origins  origins
+	problems []string
+
+	// version is only a flag
+	version bool
 }
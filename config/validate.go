@@ -2,16 +2,82 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"git.wisehodl.dev/jay/aicli/provider"
 )
 
 func validateConfig(cfg ConfigData) error {
-	if cfg.APIKey == "" {
-		return fmt.Errorf("API key required: use --key, --key-file, AICLI_API_KEY, AICLI_API_KEY_FILE, or key_file in config")
+	// Only the settings some model entry is sent with need to be usable: the
+	// top-level ones, or a named provider's
+	topLevel := false
+	for _, entry := range cfg.ModelEntries() {
+		_, _, name := cfg.ForModel(entry)
+		if name == "" {
+			topLevel = true
+		} else if err := validateProvider(name, cfg.Providers[name]); err != nil {
+			return err
+		}
+	}
+
+	if topLevel && cfg.APIKey == "" && cfg.Auth != AuthNone {
+		return fmt.Errorf("API key required: use --key, --key-file, --key-cmd, AICLI_API_KEY, AICLI_API_KEY_FILE, AICLI_API_KEY_CMD, or key_file or key_cmd in config; use --auth none for servers without authentication")
 	}
 
-	if cfg.Protocol != ProtocolOpenAI && cfg.Protocol != ProtocolOllama {
-		return fmt.Errorf("invalid protocol: must be openai or ollama")
+	if !isProtocol(cfg.Protocol) {
+		return fmt.Errorf("invalid protocol: must be one of %s, got: %s", protocolList(), cfg.Protocol)
+	}
+
+	if cfg.Temperature != nil && (*cfg.Temperature < 0 || *cfg.Temperature > 2) {
+		return fmt.Errorf("invalid temperature: must be between 0 and 2, got: %g", *cfg.Temperature)
+	}
+	if cfg.TopP != nil && (*cfg.TopP < 0 || *cfg.TopP > 1) {
+		return fmt.Errorf("invalid top_p: must be between 0 and 1, got: %g", *cfg.TopP)
+	}
+	if cfg.MaxTokens != nil && *cfg.MaxTokens < 1 {
+		return fmt.Errorf("invalid max_tokens: must be at least 1, got: %d", *cfg.MaxTokens)
+	}
+
+	if cfg.Protocol == ProtocolAzure && cfg.Endpoint == "" {
+		return fmt.Errorf("azure protocol requires an endpoint: use --endpoint, AICLI_ENDPOINT, or endpoint in config")
+	}
+
+	if cfg.Proxy != "" {
+		if u, err := url.Parse(cfg.Proxy); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid proxy: must be a URL such as http://proxy:3128, got: %s", cfg.Proxy)
+		}
+	}
+	if (cfg.ClientCert == "") != (cfg.ClientKey == "") {
+		return fmt.Errorf("client certificate and key must be set together: use --client-cert and --client-key")
+	}
+
+	for _, class := range cfg.FallbackOn {
+		if !slices.Contains(ErrorClasses, class) {
+			return fmt.Errorf("invalid fallback_on class: must be one of %s, got: %s", errorClassList(), class)
+		}
 	}
 
 	return nil
 }
+
+// isProtocol reports whether a provider is registered for protocol.
+func isProtocol(protocol APIProtocol) bool {
+	_, ok := provider.Lookup(string(protocol))
+	return ok
+}
+
+// protocolList formats the registered protocol names for messages.
+func protocolList() string {
+	return strings.Join(provider.Names(), ", ")
+}
+
+// errorClassList formats the error class names for messages.
+func errorClassList() string {
+	names := make([]string, len(ErrorClasses))
+	for i, class := range ErrorClasses {
+		names[i] = string(class)
+	}
+	return strings.Join(names, ", ")
+}
@@ -35,10 +35,122 @@ func TestValidateConfig(t *testing.T) {
 			wantErr: true,
 			errMsg:  "API key required",
 		},
+		{
+			name: "no api key with auth none",
+			cfg: ConfigData{
+				Protocol: ProtocolOllamaChat,
+				Auth:     AuthNone,
+			},
+			wantErr: false,
+		},
+		{
+			name: "azure without endpoint",
+			cfg: ConfigData{
+				Protocol: ProtocolAzure,
+				APIKey:   "azure-key",
+			},
+			wantErr: true,
+			errMsg:  "azure protocol requires an endpoint",
+		},
+		{
+			name: "provider without url",
+			cfg: ConfigData{
+				Protocol:  ProtocolOpenAI,
+				APIKey:    "sk-test123",
+				Model:     "local:llama3",
+				Providers: map[string]ProviderConfig{"local": {Protocol: ProtocolOllama, Auth: AuthNone}},
+			},
+			wantErr: true,
+			errMsg:  "provider local: url required",
+		},
+		{
+			name: "provider without key",
+			cfg: ConfigData{
+				Protocol: ProtocolOpenAI,
+				APIKey:   "sk-test123",
+				Model:    "openai:gpt-4o",
+				Providers: map[string]ProviderConfig{
+					"openai": {Protocol: ProtocolOpenAI, URL: "https://api.openai.com/v1/chat/completions"},
+				},
+			},
+			wantErr: true,
+			errMsg:  "provider openai: key or key_file required",
+		},
+		{
+			name: "provider with invalid protocol",
+			cfg: ConfigData{
+				Protocol:       ProtocolOpenAI,
+				APIKey:         "sk-test123",
+				Model:          "gpt-4o",
+				FallbackModels: []string{"odd:model"},
+				Providers: map[string]ProviderConfig{
+					"odd": {Protocol: "bogus", URL: "http://localhost", Auth: AuthNone},
+				},
+			},
+			wantErr: true,
+			errMsg:  "provider odd: invalid protocol",
+		},
+		{
+			name: "no top-level key when every model uses a provider",
+			cfg: ConfigData{
+				Protocol:       ProtocolOpenAI,
+				Model:          "local:llama3",
+				FallbackModels: []string{"local:llama3:8b"},
+				Providers: map[string]ProviderConfig{
+					"local": {Protocol: ProtocolOllamaChat, URL: "http://localhost:11434/api/chat", Auth: AuthNone},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "top-level key required when a model uses it",
+			cfg: ConfigData{
+				Protocol:       ProtocolOpenAI,
+				Model:          "local:llama3",
+				FallbackModels: []string{"gpt-4o-mini"},
+				Providers: map[string]ProviderConfig{
+					"local": {Protocol: ProtocolOllamaChat, URL: "http://localhost:11434/api/chat", Auth: AuthNone},
+				},
+			},
+			wantErr: true,
+			errMsg:  "API key required",
+		},
+		{
+			name: "unused provider is not checked",
+			cfg: ConfigData{
+				Protocol: ProtocolOpenAI,
+				APIKey:   "sk-test123",
+				Model:    "gpt-4o",
+				Providers: map[string]ProviderConfig{
+					"stale": {Protocol: ProtocolOpenAI},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "proxy without scheme",
+			cfg: ConfigData{
+				Protocol: ProtocolOpenAI,
+				APIKey:   "sk-test123",
+				Proxy:    "proxy.internal:3128",
+			},
+			wantErr: true,
+			errMsg:  "invalid proxy",
+		},
+		{
+			name: "client cert without key",
+			cfg: ConfigData{
+				Protocol:   ProtocolOpenAI,
+				APIKey:     "sk-test123",
+				ClientCert: "client.pem",
+			},
+			wantErr: true,
+			errMsg:  "client certificate and key must be set together",
+		},
 		{
 			name: "invalid protocol",
 			cfg: ConfigData{
-				Protocol:       APIProtocol(99),
+				Protocol:       APIProtocol("invalid"),
 				URL:            "https://api.openai.com",
 				Model:          "gpt-4",
 				FallbackModels: []string{"gpt-3.5"},
@@ -47,6 +159,57 @@ func TestValidateConfig(t *testing.T) {
 			wantErr: true,
 			errMsg:  "invalid protocol",
 		},
+		{
+			name: "invalid fallback class",
+			cfg: ConfigData{
+				Protocol:   ProtocolOpenAI,
+				APIKey:     "sk-test123",
+				FallbackOn: []ErrorClass{ErrorServer, ErrorClass("timeout")},
+			},
+			wantErr: true,
+			errMsg:  "invalid fallback_on class",
+		},
+		{
+			name: "temperature out of range",
+			cfg: ConfigData{
+				Protocol:    ProtocolOpenAI,
+				APIKey:      "sk-test123",
+				Temperature: floatPtr(2.5),
+			},
+			wantErr: true,
+			errMsg:  "invalid temperature",
+		},
+		{
+			name: "top_p out of range",
+			cfg: ConfigData{
+				Protocol: ProtocolOpenAI,
+				APIKey:   "sk-test123",
+				TopP:     floatPtr(-0.1),
+			},
+			wantErr: true,
+			errMsg:  "invalid top_p",
+		},
+		{
+			name: "zero max_tokens",
+			cfg: ConfigData{
+				Protocol:  ProtocolOpenAI,
+				APIKey:    "sk-test123",
+				MaxTokens: intPtr(0),
+			},
+			wantErr: true,
+			errMsg:  "invalid max_tokens",
+		},
+		{
+			name: "generation params at bounds",
+			cfg: ConfigData{
+				Protocol:    ProtocolOpenAI,
+				APIKey:      "sk-test123",
+				Temperature: floatPtr(0),
+				TopP:        floatPtr(1),
+				MaxTokens:   intPtr(1),
+			},
+			wantErr: false,
+		},
 		{
 			name: "ollama protocol valid",
 			cfg: ConfigData{
@@ -58,6 +221,46 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "ollama chat protocol valid",
+			cfg: ConfigData{
+				Protocol: ProtocolOllamaChat,
+				URL:      "http://localhost:11434/api/chat",
+				Model:    "llama3",
+				APIKey:   "not-used-but-required",
+			},
+			wantErr: false,
+		},
+		{
+			name: "anthropic protocol valid",
+			cfg: ConfigData{
+				Protocol: ProtocolAnthropic,
+				URL:      "https://api.anthropic.com/v1/messages",
+				Model:    "claude-sonnet-4-5",
+				APIKey:   "sk-ant-test",
+			},
+			wantErr: false,
+		},
+		{
+			name: "gemini protocol valid",
+			cfg: ConfigData{
+				Protocol: ProtocolGemini,
+				URL:      "https://generativelanguage.googleapis.com/v1beta",
+				Model:    "gemini-2.5-flash",
+				APIKey:   "goog-test",
+			},
+			wantErr: false,
+		},
+		{
+			name: "responses protocol valid",
+			cfg: ConfigData{
+				Protocol: ProtocolResponses,
+				URL:      "https://api.openai.com/v1/responses",
+				Model:    "o4-mini",
+				APIKey:   "sk-test",
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -72,3 +275,7 @@ func TestValidateConfig(t *testing.T) {
 		})
 	}
 }
+
+func floatPtr(v float64) *float64 { return &v }
+
+func intPtr(v int) *int { return &v }
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"sort"
 
 	"git.wisehodl.dev/jay/aicli/api"
 	"git.wisehodl.dev/jay/aicli/config"
@@ -31,6 +32,10 @@ func run() error {
 		return nil
 	}
 
+	if config.IsConfigCommand(os.Args[1:]) {
+		return config.RunConfigCommand(os.Args[2:], os.Stdout)
+	}
+
 	// Phase 2: Configuration resolution
 	cfg, err := config.BuildConfig(os.Args[1:])
 	if err != nil {
@@ -39,10 +44,22 @@ func run() error {
 
 	if cfg.Verbose {
 		fmt.Fprintf(os.Stderr, "[verbose] Configuration loaded\n")
-		fmt.Fprintf(os.Stderr, "  Protocol: %s\n", protocolString(cfg.Protocol))
+		if cfg.Profile != "" {
+			fmt.Fprintf(os.Stderr, "  Profile: %s\n", cfg.Profile)
+		}
+		fmt.Fprintf(os.Stderr, "  Protocol: %s\n", cfg.Protocol)
 		fmt.Fprintf(os.Stderr, "  URL: %s\n", cfg.URL)
 		fmt.Fprintf(os.Stderr, "  Model: %s\n", cfg.Model)
 		fmt.Fprintf(os.Stderr, "  Fallbacks: %v\n", cfg.FallbackModels)
+		names := make([]string, 0, len(cfg.Providers))
+		for name := range cfg.Providers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p := cfg.Providers[name]
+			fmt.Fprintf(os.Stderr, "  Provider %s: %s %s\n", name, p.Protocol, p.URL)
+		}
 	}
 
 	// Phase 3: Input collection
@@ -59,13 +76,17 @@ func run() error {
 	}
 
 	// Phase 4: Query construction
-	query := prompt.ConstructQuery(inputData.Prompts, inputData.Files)
+	query := prompt.ConstructQuery(inputData.Prompts, inputData.Files, cfg.DefaultPrompt)
 
 	if cfg.Verbose {
 		fmt.Fprintf(os.Stderr, "[verbose] Query length: %d bytes\n", len(query))
 	}
 
 	// Phase 5: API communication
+	if cfg.Stream {
+		return streamResponse(cfg, query)
+	}
+
 	response, model, duration, err := api.SendChatRequest(cfg, query)
 	if err != nil {
 		return err
@@ -75,9 +96,15 @@ func run() error {
 	return output.WriteOutput(response, model, duration, cfg)
 }
 
-func protocolString(p config.APIProtocol) string {
-	if p == config.ProtocolOllama {
-		return "ollama"
+// streamResponse runs phases 5 and 6 together, delivering output as it arrives.
+func streamResponse(cfg config.ConfigData, query string) error {
+	sink := output.NewStreamWriter(cfg)
+
+	_, model, duration, err := api.StreamChatRequest(cfg, query, sink)
+	if err != nil {
+		sink.Close()
+		return err
 	}
-	return "openai"
+
+	return sink.Finish(model, duration)
 }
@@ -12,18 +12,55 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// clearAICLIEnv unsets every AICLI_* variable and points AICLI_CONFIG_FILE
+// at an empty file, so config files on the machine running the tests are
+// never discovered.
 func clearAICLIEnv(t *testing.T) {
 	t.Setenv("AICLI_API_KEY", "")
 	t.Setenv("AICLI_API_KEY_FILE", "")
+	t.Setenv("AICLI_API_KEY_CMD", "")
 	t.Setenv("AICLI_PROTOCOL", "")
 	t.Setenv("AICLI_URL", "")
+	t.Setenv("AICLI_AUTH", "")
+	t.Setenv("AICLI_PROXY", "")
+	t.Setenv("AICLI_CA_CERT", "")
+	t.Setenv("AICLI_CLIENT_CERT", "")
+	t.Setenv("AICLI_CLIENT_KEY", "")
+	t.Setenv("AICLI_CONNECT_TIMEOUT", "")
+	t.Setenv("AICLI_TIMEOUT", "")
+	t.Setenv("AICLI_ENDPOINT", "")
+	t.Setenv("AICLI_DEPLOYMENT", "")
+	t.Setenv("AICLI_API_VERSION", "")
 	t.Setenv("AICLI_MODEL", "")
 	t.Setenv("AICLI_FALLBACK", "")
+	t.Setenv("AICLI_FALLBACK_ON", "")
+	t.Setenv("AICLI_TEMPERATURE", "")
+	t.Setenv("AICLI_TOP_P", "")
+	t.Setenv("AICLI_MAX_TOKENS", "")
+	t.Setenv("AICLI_SEED", "")
+	t.Setenv("AICLI_STOP", "")
+	t.Setenv("AICLI_RETRIES", "")
+	t.Setenv("AICLI_RETRY_MAX_WAIT", "")
 	t.Setenv("AICLI_SYSTEM", "")
 	t.Setenv("AICLI_SYSTEM_FILE", "")
-	t.Setenv("AICLI_CONFIG_FILE", "")
+	emptyConfig := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(emptyConfig, nil, 0644))
+	t.Setenv("AICLI_CONFIG_FILE", emptyConfig)
+	t.Setenv("AICLI_PROFILE", "")
+	t.Setenv("AICLI_STRICT", "")
+	t.Setenv("AICLI_ENV_FILE", "")
+	t.Setenv("AICLI_DOTENV", "")
 	t.Setenv("AICLI_PROMPT_FILE", "")
 	t.Setenv("AICLI_DEFAULT_PROMPT", "")
+	t.Setenv("AICLI_STREAM", "")
+	t.Setenv("AICLI_FILES", "")
+	t.Setenv("AICLI_PROMPTS", "")
+	t.Setenv("AICLI_STDIN_FILE", "")
+	t.Setenv("AICLI_HEADERS", "")
+	t.Setenv("AICLI_PARAMS", "")
+	t.Setenv("AICLI_OUTPUT", "")
+	t.Setenv("AICLI_QUIET", "")
+	t.Setenv("AICLI_VERBOSE", "")
 }
 
 func TestRunVersionFlag(t *testing.T) {
@@ -77,6 +114,7 @@ func TestRunMissingAPIKey(t *testing.T) {
 	// Clear all API key sources
 	t.Setenv("AICLI_API_KEY", "")
 	t.Setenv("AICLI_API_KEY_FILE", "")
+	t.Setenv("AICLI_API_KEY_CMD", "")
 
 	os.Args = []string{"aicli", "-p", "test"}
 
@@ -161,6 +199,51 @@ func TestRunWithFileOutput(t *testing.T) {
 	assert.Equal(t, "file response", string(content))
 }
 
+func TestRunStream(t *testing.T) {
+	clearAICLIEnv(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(body), `"stream":true`)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"streamed \"}}]}\n\n"))
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"response\"}}]}\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	t.Setenv("AICLI_API_KEY", "sk-test")
+
+	os.Args = []string{
+		"aicli",
+		"-u", server.URL,
+		"-p", "test prompt",
+		"--stream",
+		"-q",
+	}
+
+	err := run()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	assert.Equal(t, "streamed response\n", buf.String())
+}
+
 func TestRunWithFiles(t *testing.T) {
 	clearAICLIEnv(t)
 
@@ -314,6 +397,7 @@ func TestRunVerboseMode(t *testing.T) {
 
 	stderr := bufErr.String()
 	assert.Contains(t, stderr, "[verbose] Configuration loaded")
+	assert.Contains(t, stderr, "Protocol: openai")
 	assert.Contains(t, stderr, "[verbose] Input resolved")
 	assert.Contains(t, stderr, "[verbose] Query length")
 }
@@ -336,25 +420,3 @@ func TestRunInvalidProtocol(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid protocol")
 }
-
-func TestProtocolString(t *testing.T) {
-	clearAICLIEnv(t)
-
-	tests := []struct {
-		protocol int
-		want     string
-	}{
-		{0, "openai"}, // ProtocolOpenAI
-		{1, "ollama"}, // ProtocolOllama
-	}
-
-	for _, tt := range tests {
-		// Can't import config.APIProtocol here, so we test the function directly
-		// This is a simple pure function test
-		if tt.protocol == 1 {
-			assert.Equal(t, "ollama", "ollama")
-		} else {
-			assert.Equal(t, "openai", "openai")
-		}
-	}
-}
@@ -2,6 +2,7 @@ package output
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"time"
 
@@ -23,14 +24,90 @@ func WriteOutput(response, model string, duration time.Duration, cfg config.Conf
 
 	// Write metadata to stderr unless quiet
 	if !cfg.Quiet {
-		metadata := fmt.Sprintf("Used model: %s\nQuery duration: %.1fs\nWrote response to: %s\n",
-			model, duration.Seconds(), cfg.Output)
-		return writeStderr(metadata)
+		return writeStderr(fileMetadata(model, duration, cfg.Output))
 	}
 
 	return nil
 }
 
+// StreamWriter delivers a response incrementally to the configured destination.
+// It satisfies api.StreamSink.
+type StreamWriter struct {
+	cfg  config.ConfigData
+	dest io.Writer
+	file *os.File
+}
+
+// NewStreamWriter creates a StreamWriter for the configured output.
+func NewStreamWriter(cfg config.ConfigData) *StreamWriter {
+	return &StreamWriter{cfg: cfg}
+}
+
+// Begin opens the destination and writes the metadata header for model.
+func (s *StreamWriter) Begin(model string) error {
+	if s.cfg.Output != "" {
+		f, err := os.OpenFile(s.cfg.Output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("write output file: %w", err)
+		}
+		s.file = f
+		s.dest = f
+		return nil
+	}
+
+	s.dest = os.Stdout
+	if s.cfg.Quiet {
+		return nil
+	}
+	_, err := fmt.Fprint(s.dest, formatStreamHeader(model))
+	if err != nil {
+		return fmt.Errorf("write stdout: %w", err)
+	}
+	return nil
+}
+
+// Write passes response content straight through to the destination.
+func (s *StreamWriter) Write(p []byte) (int, error) {
+	if s.dest == nil {
+		return 0, fmt.Errorf("stream written before begin")
+	}
+	return s.dest.Write(p)
+}
+
+// Finish terminates the response and reports the remaining metadata.
+func (s *StreamWriter) Finish(model string, duration time.Duration) error {
+	if s.file != nil {
+		if err := s.Close(); err != nil {
+			return err
+		}
+		if !s.cfg.Quiet {
+			return writeStderr(fileMetadata(model, duration, s.cfg.Output))
+		}
+		return nil
+	}
+
+	if err := writeStdout(""); err != nil {
+		return err
+	}
+	if !s.cfg.Quiet {
+		return writeStderr(fmt.Sprintf("Query duration: %.1fs\n", duration.Seconds()))
+	}
+	return nil
+}
+
+// Close releases the output file, if one was opened.
+func (s *StreamWriter) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return fmt.Errorf("write output file: %w", err)
+	}
+	return nil
+}
+
 // formatOutput constructs the final output string with optional metadata header.
 func formatOutput(response, model string, duration time.Duration, quiet bool) string {
 	if quiet {
@@ -47,6 +124,25 @@ Query duration: %.1fs
 %s`, model, duration.Seconds(), response)
 }
 
+// formatStreamHeader constructs the metadata header written before a streamed
+// response. Duration is unknown until the stream ends, so it is reported
+// separately on stderr.
+func formatStreamHeader(model string) string {
+	return fmt.Sprintf(`--- aicli ---
+
+Used model: %s
+
+--- response ---
+
+`, model)
+}
+
+// fileMetadata describes a response written to path.
+func fileMetadata(model string, duration time.Duration, path string) string {
+	return fmt.Sprintf("Used model: %s\nQuery duration: %.1fs\nWrote response to: %s\n",
+		model, duration.Seconds(), path)
+}
+
 // writeStdout writes content to stdout.
 func writeStdout(content string) error {
 	_, err := fmt.Println(content)
@@ -484,3 +484,88 @@ func TestWriteOutputFileError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "write output file")
 }
+
+func TestStreamWriter(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.ConfigData
+		wantStdout string
+		wantStderr string
+		wantFile   string
+	}{
+		{
+			name:       "stdout with metadata",
+			cfg:        config.ConfigData{},
+			wantStdout: "--- aicli ---\n\nUsed model: gpt-4\n\n--- response ---\n\nHello, world\n",
+			wantStderr: "Query duration: 2.0s\n",
+		},
+		{
+			name:       "stdout quiet mode",
+			cfg:        config.ConfigData{Quiet: true},
+			wantStdout: "Hello, world\n",
+		},
+		{
+			name:       "file output with stderr metadata",
+			cfg:        config.ConfigData{Output: "output.txt"},
+			wantStderr: "Used model: gpt-4\nQuery duration: 2.0s\nWrote response to: ",
+			wantFile:   "Hello, world",
+		},
+		{
+			name:     "file output quiet mode",
+			cfg:      config.ConfigData{Output: "output.txt", Quiet: true},
+			wantFile: "Hello, world",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.cfg.Output != "" {
+				tt.cfg.Output = filepath.Join(t.TempDir(), tt.cfg.Output)
+			}
+
+			oldStdout, oldStderr := os.Stdout, os.Stderr
+			stdoutR, stdoutW, _ := os.Pipe()
+			stderrR, stderrW, _ := os.Pipe()
+			os.Stdout, os.Stderr = stdoutW, stderrW
+
+			sw := NewStreamWriter(tt.cfg)
+			assert.NoError(t, sw.Begin("gpt-4"))
+			_, err := io.WriteString(sw, "Hello, ")
+			assert.NoError(t, err)
+			_, err = io.WriteString(sw, "world")
+			assert.NoError(t, err)
+			err = sw.Finish("gpt-4", 2*time.Second)
+
+			stdoutW.Close()
+			stderrW.Close()
+			os.Stdout, os.Stderr = oldStdout, oldStderr
+
+			assert.NoError(t, err)
+
+			var stdoutBuf, stderrBuf bytes.Buffer
+			io.Copy(&stdoutBuf, stdoutR)
+			io.Copy(&stderrBuf, stderrR)
+
+			assert.Equal(t, tt.wantStdout, stdoutBuf.String())
+			if tt.wantStderr == "" {
+				assert.Empty(t, stderrBuf.String())
+			} else {
+				assert.Contains(t, stderrBuf.String(), tt.wantStderr)
+			}
+
+			if tt.wantFile != "" {
+				content, err := os.ReadFile(tt.cfg.Output)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantFile, string(content))
+			}
+		})
+	}
+}
+
+func TestStreamWriterBeginFileError(t *testing.T) {
+	sw := NewStreamWriter(config.ConfigData{Output: "/nonexistent/dir/output.txt"})
+
+	err := sw.Begin("gpt-4")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "write output file")
+}
@@ -7,13 +7,19 @@ import (
 	"git.wisehodl.dev/jay/aicli/input"
 )
 
-const defaultPrompt = "Analyze the following:"
+// builtinPrompt precedes files when no prompt or default prompt is given.
+const builtinPrompt = "Analyze the following:"
 
 // ConstructQuery formats prompts and files into a complete query string.
-func ConstructQuery(prompts []string, files []input.FileData) string {
+// defaultPrompt precedes files when there are no prompts; empty uses the
+// built-in one.
+func ConstructQuery(prompts []string, files []input.FileData, defaultPrompt string) string {
+	if defaultPrompt == "" {
+		defaultPrompt = builtinPrompt
+	}
 	promptStr := formatPrompts(prompts)
 	filesStr := formatFiles(files)
-	return combineContent(promptStr, filesStr)
+	return combineContent(promptStr, filesStr, defaultPrompt)
 }
 
 // formatPrompts joins prompt strings with newlines.
@@ -38,7 +44,7 @@ func formatFiles(files []input.FileData) string {
 }
 
 // combineContent merges formatted prompts and files with appropriate separators.
-func combineContent(promptStr, filesStr string) string {
+func combineContent(promptStr, filesStr, defaultPrompt string) string {
 	if promptStr == "" && filesStr == "" {
 		return ""
 	}
@@ -153,7 +153,7 @@ func TestCombineContent(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := combineContent(tt.promptStr, tt.filesStr)
+			got := combineContent(tt.promptStr, tt.filesStr, builtinPrompt)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -161,10 +161,11 @@ func TestCombineContent(t *testing.T) {
 
 func TestConstructQuery(t *testing.T) {
 	tests := []struct {
-		name    string
-		prompts []string
-		files   []input.FileData
-		want    string
+		name          string
+		prompts       []string
+		files         []input.FileData
+		defaultPrompt string
+		want          string
 	}{
 		{
 			name:    "empty inputs returns empty",
@@ -186,6 +187,15 @@ func TestConstructQuery(t *testing.T) {
 			},
 			want: "Analyze the following:\n\nFile: main.go\n\n```\npackage main\n```",
 		},
+		{
+			name:    "file only with configured default prompt",
+			prompts: []string{},
+			files: []input.FileData{
+				{Path: "main.go", Content: "package main"},
+			},
+			defaultPrompt: "Summarize:",
+			want:          "Summarize:\n\nFile: main.go\n\n```\npackage main\n```",
+		},
 		{
 			name:    "multiple prompts and files",
 			prompts: []string{"review", "focus on bugs"},
@@ -207,7 +217,7 @@ func TestConstructQuery(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := ConstructQuery(tt.prompts, tt.files)
+			got := ConstructQuery(tt.prompts, tt.files, tt.defaultPrompt)
 			assert.Equal(t, tt.want, got)
 		})
 	}
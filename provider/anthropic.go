@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// anthropicVersion is the Messages API version requested from Anthropic.
+	anthropicVersion = "2023-06-01"
+
+	// anthropicMaxTokens is sent as the required max_tokens field.
+	anthropicMaxTokens = 4096
+)
+
+// anthropicParams has no seed; max_tokens overrides anthropicMaxTokens.
+var anthropicParams = paramFields{
+	temperature: "temperature",
+	topP:        "top_p",
+	maxTokens:   "max_tokens",
+	stop:        "stop_sequences",
+}
+
+// anthropic implements the Anthropic Messages API.
+type anthropic struct{}
+
+func init() { Register(anthropic{}) }
+
+func (anthropic) Name() string { return "anthropic" }
+
+func (anthropic) Endpoint(req Request) string { return req.URL }
+
+func (anthropic) Payload(req Request) map[string]interface{} {
+	// Anthropic takes the system prompt as a top-level field, not a message
+	payload := map[string]interface{}{
+		"model":      req.Model,
+		"max_tokens": anthropicMaxTokens,
+		"messages": []map[string]string{
+			{"role": "user", "content": req.Query},
+		},
+	}
+	if req.System != "" {
+		payload["system"] = req.System
+	}
+	anthropicParams.set(payload, req.Params)
+	if req.Stream {
+		payload["stream"] = true
+	}
+	return payload
+}
+
+func (anthropic) Headers(h http.Header) {
+	h.Set("anthropic-version", anthropicVersion)
+}
+
+func (anthropic) Authorize(h http.Header, apiKey string) {
+	h.Set("x-api-key", apiKey)
+}
+
+// ParseResponse joins the text blocks of a message response.
+func (anthropic) ParseResponse(body []byte) (string, error) {
+	result, err := decodeResponse(body)
+	if err != nil {
+		return "", err
+	}
+
+	blocks, ok := result["content"].([]interface{})
+	if !ok {
+		return "", fmt.Errorf("no content in anthropic response")
+	}
+
+	var text strings.Builder
+	found := false
+	for _, b := range blocks {
+		block, ok := b.(map[string]interface{})
+		if !ok || block["type"] != "text" {
+			continue
+		}
+		if s, ok := block["text"].(string); ok {
+			text.WriteString(s)
+			found = true
+		}
+	}
+
+	if !found {
+		return "", fmt.Errorf("no text blocks in anthropic response")
+	}
+
+	return text.String(), nil
+}
+
+func (anthropic) ParseStream(body io.Reader, w io.Writer) (string, error) {
+	return readSSE(body, w, parseAnthropicEvent)
+}
+
+func (anthropic) DecodeError(status int, header http.Header, body []byte) error {
+	return decodeAPIError(status, header, body)
+}
+
+// parseAnthropicEvent extracts text deltas from a single stream event.
+// The event type is repeated in the data payload, so "event:" lines are not needed.
+func parseAnthropicEvent(data []byte) (string, bool, error) {
+	event, err := decodeChunk(data)
+	if err != nil {
+		return "", false, err
+	}
+
+	switch event["type"] {
+	case "content_block_delta":
+		delta, _ := event["delta"].(map[string]interface{})
+		text, _ := delta["text"].(string)
+		return text, false, nil
+	case "message_stop":
+		return "", true, nil
+	case "error":
+		return "", false, streamError(event["error"])
+	}
+
+	return "", false, nil
+}
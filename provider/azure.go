@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// azureAPIVersion is used when no api_version is configured.
+const azureAPIVersion = "2024-10-21"
+
+// azure implements Azure OpenAI chat completions. The payload and responses
+// match OpenAI; the model names a deployment in the URL instead of the body.
+type azure struct{ openAI }
+
+func init() { Register(azure{}) }
+
+func (azure) Name() string { return "azure" }
+
+// Endpoint builds the deployment URL from the resource endpoint in req.URL.
+func (azure) Endpoint(req Request) string {
+	version := req.APIVersion
+	if version == "" {
+		version = azureAPIVersion
+	}
+	return strings.TrimRight(req.URL, "/") + "/openai/deployments/" + url.PathEscape(req.Model) +
+		"/chat/completions?api-version=" + url.QueryEscape(version)
+}
+
+func (a azure) Payload(req Request) map[string]interface{} {
+	payload := a.openAI.Payload(req)
+	delete(payload, "model")
+	return payload
+}
+
+func (azure) Authorize(h http.Header, apiKey string) {
+	h.Set("api-key", apiKey)
+}
@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody bounds how much of an unrecognized error body is shown.
+const maxErrorBody = 200
+
+// requestIDHeaders are the response headers providers use for request IDs.
+var requestIDHeaders = []string{"x-request-id", "request-id"}
+
+// APIError is a non-200 response decoded from the provider's error envelope.
+type APIError struct {
+	StatusCode int
+	Code       string // provider error code, e.g. "invalid_api_key"
+	Type       string // provider error type, e.g. "invalid_request_error"
+	Message    string
+	RequestID  string
+}
+
+// Error renders a single line: status, message, then code and request ID
+// when known.
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "HTTP %d", e.StatusCode)
+	if e.Message != "" {
+		b.WriteString(": " + e.Message)
+	}
+	if code := e.Code; code != "" || e.Type != "" {
+		if code == "" {
+			code = e.Type
+		}
+		fmt.Fprintf(&b, " (%s)", code)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " [request %s]", e.RequestID)
+	}
+	return b.String()
+}
+
+// decodeAPIError is the default DecodeError. It understands the common
+// envelopes:
+//
+//	{"error": "message"}                                      Ollama
+//	{"error": {"message": ..., "type": ..., "code": ...}}     OpenAI, Anthropic
+//	{"error": {"message": ..., "status": ..., "code": 400}}   Gemini
+//
+// Anything else is reported as the first line of the raw body.
+func decodeAPIError(status int, header http.Header, body []byte) error {
+	e := &APIError{StatusCode: status}
+
+	var envelope struct {
+		Error     json.RawMessage `json:"error"`
+		Message   string          `json:"message"`
+		RequestID string          `json:"request_id"`
+	}
+	if json.Unmarshal(body, &envelope) == nil {
+		e.RequestID = envelope.RequestID
+		e.Message = envelope.Message
+
+		var msg string
+		var detail struct {
+			Message string      `json:"message"`
+			Type    string      `json:"type"`
+			Code    interface{} `json:"code"`
+			Status  string      `json:"status"`
+		}
+		if json.Unmarshal(envelope.Error, &msg) == nil {
+			e.Message = msg
+		} else if json.Unmarshal(envelope.Error, &detail) == nil {
+			e.Message = detail.Message
+			e.Type = detail.Type
+			if code, ok := detail.Code.(string); ok {
+				e.Code = code
+			} else {
+				e.Code = detail.Status
+			}
+		}
+	}
+
+	if e.Message == "" {
+		e.Message = firstLine(string(body))
+	}
+	e.Message = strings.Join(strings.Fields(e.Message), " ")
+
+	if e.RequestID == "" {
+		for _, name := range requestIDHeaders {
+			if id := header.Get(name); id != "" {
+				e.RequestID = id
+				break
+			}
+		}
+	}
+
+	return e
+}
+
+// firstLine returns the first non-blank line of s, truncated for display.
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	if len(s) > maxErrorBody {
+		s = s[:maxErrorBody] + "..."
+	}
+	return s
+}
@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeAPIError(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		header  http.Header
+		body    string
+		want    APIError
+		wantMsg string
+	}{
+		{
+			name:   "openai envelope",
+			status: 401,
+			header: http.Header{"X-Request-Id": {"req_123"}},
+			body:   `{"error":{"message":"Incorrect API key provided.","type":"invalid_request_error","code":"invalid_api_key"}}`,
+			want: APIError{
+				StatusCode: 401,
+				Code:       "invalid_api_key",
+				Type:       "invalid_request_error",
+				Message:    "Incorrect API key provided.",
+				RequestID:  "req_123",
+			},
+			wantMsg: "HTTP 401: Incorrect API key provided. (invalid_api_key) [request req_123]",
+		},
+		{
+			name:   "ollama envelope",
+			status: 404,
+			body:   `{"error":"model \"llama9\" not found, try pulling it first"}`,
+			want: APIError{
+				StatusCode: 404,
+				Message:    `model "llama9" not found, try pulling it first`,
+			},
+			wantMsg: `HTTP 404: model "llama9" not found, try pulling it first`,
+		},
+		{
+			name:   "anthropic envelope",
+			status: 529,
+			header: http.Header{"Request-Id": {"req_header"}},
+			body:   `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"},"request_id":"req_body"}`,
+			want: APIError{
+				StatusCode: 529,
+				Type:       "overloaded_error",
+				Message:    "Overloaded",
+				RequestID:  "req_body",
+			},
+			wantMsg: "HTTP 529: Overloaded (overloaded_error) [request req_body]",
+		},
+		{
+			name:   "gemini envelope",
+			status: 400,
+			body:   `{"error":{"code":400,"message":"API key not valid.\nPlease pass a valid API key.","status":"INVALID_ARGUMENT"}}`,
+			want: APIError{
+				StatusCode: 400,
+				Code:       "INVALID_ARGUMENT",
+				Message:    "API key not valid. Please pass a valid API key.",
+			},
+			wantMsg: "HTTP 400: API key not valid. Please pass a valid API key. (INVALID_ARGUMENT)",
+		},
+		{
+			name:   "plain text body",
+			status: 502,
+			body:   "Bad Gateway\n<html>...</html>",
+			want: APIError{
+				StatusCode: 502,
+				Message:    "Bad Gateway",
+			},
+			wantMsg: "HTTP 502: Bad Gateway",
+		},
+		{
+			name:    "empty body",
+			status:  503,
+			want:    APIError{StatusCode: 503},
+			wantMsg: "HTTP 503",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			err := decodeAPIError(tt.status, header, []byte(tt.body))
+
+			var apiErr *APIError
+			assert.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tt.want, *apiErr)
+			assert.Equal(t, tt.wantMsg, err.Error())
+		})
+	}
+}
+
+func TestDecodeAPIErrorTruncatesBody(t *testing.T) {
+	err := decodeAPIError(500, http.Header{}, []byte(strings.Repeat("x", 500)))
+	assert.Len(t, err.(*APIError).Message, maxErrorBody+len("..."))
+}
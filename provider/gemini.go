@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// geminiParams are sent under generationConfig.
+var geminiParams = paramFields{
+	temperature: "temperature",
+	topP:        "topP",
+	maxTokens:   "maxOutputTokens",
+	seed:        "seed",
+	stop:        "stopSequences",
+}
+
+// gemini implements the Google Gemini generateContent protocol.
+type gemini struct{}
+
+func init() { Register(gemini{}) }
+
+func (gemini) Name() string { return "gemini" }
+
+// Endpoint appends the model path to the configured API base URL.
+// Gemini selects the model and streaming mode by URL, not body fields.
+func (gemini) Endpoint(req Request) string {
+	method := ":generateContent"
+	if req.Stream {
+		method = ":streamGenerateContent?alt=sse"
+	}
+	return strings.TrimRight(req.URL, "/") + "/models/" + url.PathEscape(req.Model) + method
+}
+
+func (gemini) Payload(req Request) map[string]interface{} {
+	payload := map[string]interface{}{
+		"contents": []map[string]interface{}{
+			{
+				"role":  "user",
+				"parts": []map[string]string{{"text": req.Query}},
+			},
+		},
+	}
+	if req.System != "" {
+		payload["systemInstruction"] = map[string]interface{}{
+			"parts": []map[string]string{{"text": req.System}},
+		}
+	}
+	geminiParams.setNested(payload, "generationConfig", req.Params)
+	return payload
+}
+
+func (gemini) Headers(http.Header) {}
+
+func (gemini) Authorize(h http.Header, apiKey string) {
+	h.Set("x-goog-api-key", apiKey)
+}
+
+// ParseResponse joins the text parts of the first candidate.
+func (gemini) ParseResponse(body []byte) (string, error) {
+	result, err := decodeResponse(body)
+	if err != nil {
+		return "", err
+	}
+
+	candidates, ok := result["candidates"].([]interface{})
+	if !ok || len(candidates) == 0 {
+		if feedback, ok := result["promptFeedback"].(map[string]interface{}); ok {
+			if reason, ok := feedback["blockReason"].(string); ok {
+				return "", fmt.Errorf("prompt blocked: %s", reason)
+			}
+		}
+		return "", fmt.Errorf("no candidates in gemini response")
+	}
+
+	text, ok := geminiCandidateText(candidates[0])
+	if !ok {
+		return "", fmt.Errorf("no content in candidate")
+	}
+	return text, nil
+}
+
+// ParseStream reads streamed responses; Gemini signals completion by closing
+// the stream.
+func (gemini) ParseStream(body io.Reader, w io.Writer) (string, error) {
+	return readSSE(body, w, func(data []byte) (string, bool, error) {
+		chunk, err := decodeChunk(data)
+		if err != nil {
+			return "", false, err
+		}
+
+		if errObj, ok := chunk["error"]; ok {
+			return "", false, streamError(errObj)
+		}
+
+		candidates, _ := chunk["candidates"].([]interface{})
+		if len(candidates) == 0 {
+			return "", false, nil
+		}
+
+		text, _ := geminiCandidateText(candidates[0])
+		return text, false, nil
+	})
+}
+
+func (gemini) DecodeError(status int, header http.Header, body []byte) error {
+	return decodeAPIError(status, header, body)
+}
+
+// geminiCandidateText joins the text parts of a single candidate.
+func geminiCandidateText(c interface{}) (string, bool) {
+	candidate, ok := c.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	content, ok := candidate["content"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	parts, ok := content["parts"].([]interface{})
+	if !ok {
+		return "", false
+	}
+
+	var text strings.Builder
+	for _, p := range parts {
+		part, _ := p.(map[string]interface{})
+		if s, ok := part["text"].(string); ok {
+			text.WriteString(s)
+		}
+	}
+	return text.String(), true
+}
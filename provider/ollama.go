@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ollamaParams are the model options shared by both Ollama protocols.
+var ollamaParams = paramFields{
+	temperature: "temperature",
+	topP:        "top_p",
+	maxTokens:   "num_predict",
+	seed:        "seed",
+	stop:        "stop",
+}
+
+// ollama implements Ollama's /api/generate protocol.
+type ollama struct{}
+
+func init() { Register(ollama{}) }
+
+func (ollama) Name() string { return "ollama" }
+
+func (ollama) Endpoint(req Request) string { return req.URL }
+
+func (ollama) Payload(req Request) map[string]interface{} {
+	payload := map[string]interface{}{
+		"model":  req.Model,
+		"prompt": req.Query,
+		"stream": req.Stream,
+	}
+	if req.System != "" {
+		payload["system"] = req.System
+	}
+	ollamaParams.setNested(payload, "options", req.Params)
+	return payload
+}
+
+func (ollama) Headers(http.Header) {}
+
+func (ollama) Authorize(h http.Header, apiKey string) { setBearer(h, apiKey) }
+
+func (ollama) ParseResponse(body []byte) (string, error) {
+	result, err := decodeResponse(body)
+	if err != nil {
+		return "", err
+	}
+
+	response, ok := result["response"].(string)
+	if !ok {
+		return "", fmt.Errorf("no response field in ollama response")
+	}
+	return response, nil
+}
+
+func (ollama) ParseStream(body io.Reader, w io.Writer) (string, error) {
+	return readNDJSON(body, w, func(data []byte) (string, bool, error) {
+		chunk, err := decodeOllamaChunk(data)
+		if err != nil {
+			return "", false, err
+		}
+		text, _ := chunk["response"].(string)
+		done, _ := chunk["done"].(bool)
+		return text, done, nil
+	})
+}
+
+func (ollama) DecodeError(status int, header http.Header, body []byte) error {
+	return decodeAPIError(status, header, body)
+}
+
+// decodeOllamaChunk unmarshals a stream chunk and surfaces in-band errors.
+func decodeOllamaChunk(data []byte) (map[string]interface{}, error) {
+	chunk, err := decodeChunk(data)
+	if err != nil {
+		return nil, err
+	}
+	if msg, ok := chunk["error"].(string); ok {
+		return nil, fmt.Errorf("stream error: %s", msg)
+	}
+	return chunk, nil
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ollamaChat implements Ollama's /api/chat protocol.
+type ollamaChat struct{}
+
+func init() { Register(ollamaChat{}) }
+
+func (ollamaChat) Name() string { return "ollama-chat" }
+
+func (ollamaChat) Endpoint(req Request) string { return req.URL }
+
+func (ollamaChat) Payload(req Request) map[string]interface{} {
+	payload := map[string]interface{}{
+		"model":    req.Model,
+		"messages": chatMessages(req),
+		"stream":   req.Stream,
+	}
+	ollamaParams.setNested(payload, "options", req.Params)
+	return payload
+}
+
+func (ollamaChat) Headers(http.Header) {}
+
+func (ollamaChat) Authorize(h http.Header, apiKey string) { setBearer(h, apiKey) }
+
+func (ollamaChat) ParseResponse(body []byte) (string, error) {
+	result, err := decodeResponse(body)
+	if err != nil {
+		return "", err
+	}
+
+	message, ok := result["message"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("no message in ollama chat response")
+	}
+	content, ok := message["content"].(string)
+	if !ok {
+		return "", fmt.Errorf("no content in message")
+	}
+	return content, nil
+}
+
+func (ollamaChat) ParseStream(body io.Reader, w io.Writer) (string, error) {
+	return readNDJSON(body, w, func(data []byte) (string, bool, error) {
+		chunk, err := decodeOllamaChunk(data)
+		if err != nil {
+			return "", false, err
+		}
+		message, _ := chunk["message"].(map[string]interface{})
+		content, _ := message["content"].(string)
+		done, _ := chunk["done"].(bool)
+		return content, done, nil
+	})
+}
+
+func (ollamaChat) DecodeError(status int, header http.Header, body []byte) error {
+	return decodeAPIError(status, header, body)
+}
@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// openAIParams are shared by the chat completions protocol and its
+// compatible gateways.
+var openAIParams = paramFields{
+	temperature: "temperature",
+	topP:        "top_p",
+	maxTokens:   "max_tokens",
+	seed:        "seed",
+	stop:        "stop",
+}
+
+// openAI implements the OpenAI chat completions protocol, also spoken by most
+// hosted gateways.
+type openAI struct{}
+
+func init() { Register(openAI{}) }
+
+func (openAI) Name() string { return "openai" }
+
+func (openAI) Endpoint(req Request) string { return req.URL }
+
+func (openAI) Payload(req Request) map[string]interface{} {
+	payload := map[string]interface{}{
+		"model":    req.Model,
+		"messages": chatMessages(req),
+	}
+	openAIParams.set(payload, req.Params)
+	if req.Stream {
+		payload["stream"] = true
+	}
+	return payload
+}
+
+func (openAI) Headers(http.Header) {}
+
+func (openAI) Authorize(h http.Header, apiKey string) { setBearer(h, apiKey) }
+
+func (openAI) ParseResponse(body []byte) (string, error) {
+	result, err := decodeResponse(body)
+	if err != nil {
+		return "", err
+	}
+
+	choices, ok := result["choices"].([]interface{})
+	if !ok {
+		return "", fmt.Errorf("no choices in response")
+	}
+
+	if len(choices) == 0 {
+		return "", fmt.Errorf("empty choices array")
+	}
+
+	firstChoice, ok := choices[0].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("invalid choice format")
+	}
+
+	message, ok := firstChoice["message"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("no message in choice")
+	}
+
+	content, ok := message["content"].(string)
+	if !ok {
+		return "", fmt.Errorf("no content in message")
+	}
+
+	return content, nil
+}
+
+func (openAI) ParseStream(body io.Reader, w io.Writer) (string, error) {
+	return readSSE(body, w, parseOpenAIChunk)
+}
+
+func (openAI) DecodeError(status int, header http.Header, body []byte) error {
+	return decodeAPIError(status, header, body)
+}
+
+// parseOpenAIChunk extracts the content delta from a single SSE data payload.
+func parseOpenAIChunk(data []byte) (string, bool, error) {
+	chunk, err := decodeChunk(data)
+	if err != nil {
+		return "", false, err
+	}
+
+	if errObj, ok := chunk["error"]; ok {
+		return "", false, streamError(errObj)
+	}
+
+	// Usage and keep-alive chunks carry no choices
+	choices, _ := chunk["choices"].([]interface{})
+	if len(choices) == 0 {
+		return "", false, nil
+	}
+
+	firstChoice, _ := choices[0].(map[string]interface{})
+	delta, _ := firstChoice["delta"].(map[string]interface{})
+	content, _ := delta["content"].(string)
+	return content, false, nil
+}
+
+// chatMessages constructs the role-based message array shared by chat protocols.
+func chatMessages(req Request) []map[string]string {
+	messages := []map[string]string{}
+	if req.System != "" {
+		messages = append(messages, map[string]string{
+			"role":    "system",
+			"content": req.System,
+		})
+	}
+	messages = append(messages, map[string]string{
+		"role":    "user",
+		"content": req.Query,
+	})
+	return messages
+}
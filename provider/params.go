@@ -0,0 +1,50 @@
+package provider
+
+// Params are the optional generation controls. Nil and empty values are
+// unset and left out of the payload.
+type Params struct {
+	Temperature *float64
+	TopP        *float64
+	MaxTokens   *int
+	Seed        *int64
+	Stop        []string
+}
+
+// paramFields names the payload fields a protocol uses for Params. An empty
+// name means the protocol has no such field and the value is dropped.
+type paramFields struct {
+	temperature string
+	topP        string
+	maxTokens   string
+	seed        string
+	stop        string
+}
+
+// set copies the set parameters into m under the protocol's field names.
+func (f paramFields) set(m map[string]interface{}, p Params) {
+	if p.Temperature != nil && f.temperature != "" {
+		m[f.temperature] = *p.Temperature
+	}
+	if p.TopP != nil && f.topP != "" {
+		m[f.topP] = *p.TopP
+	}
+	if p.MaxTokens != nil && f.maxTokens != "" {
+		m[f.maxTokens] = *p.MaxTokens
+	}
+	if p.Seed != nil && f.seed != "" {
+		m[f.seed] = *p.Seed
+	}
+	if len(p.Stop) > 0 && f.stop != "" {
+		m[f.stop] = p.Stop
+	}
+}
+
+// setNested is set for protocols that group parameters under one key.
+// The key is omitted when no parameter is set.
+func (f paramFields) setNested(m map[string]interface{}, key string, p Params) {
+	nested := map[string]interface{}{}
+	f.set(nested, p)
+	if len(nested) > 0 {
+		m[key] = nested
+	}
+}
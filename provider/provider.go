@@ -0,0 +1,92 @@
+// Package provider adapts LLM chat APIs to a single request/response model.
+// Each protocol lives in its own file and registers itself by name in init.
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// Request carries everything a provider needs to build one API call.
+type Request struct {
+	URL        string
+	Model      string
+	System     string
+	Query      string
+	Stream     bool
+	Params     Params
+	APIVersion string // azure only
+}
+
+// Provider implements one API protocol.
+type Provider interface {
+	// Name is the protocol name used in configuration.
+	Name() string
+
+	// Endpoint returns the URL the request is posted to.
+	Endpoint(req Request) string
+
+	// Payload builds the JSON request body.
+	Payload(req Request) map[string]interface{}
+
+	// Headers sets any headers the protocol requires besides credentials.
+	Headers(h http.Header)
+
+	// Authorize sets the protocol's default authentication headers.
+	Authorize(h http.Header, apiKey string)
+
+	// ParseResponse extracts the response text from a complete response body.
+	ParseResponse(body []byte) (string, error)
+
+	// ParseStream copies streamed content from body to w as it arrives and
+	// returns the complete response.
+	ParseStream(body io.Reader, w io.Writer) (string, error)
+
+	// DecodeError converts a non-200 response into an error, normally an
+	// *APIError.
+	DecodeError(status int, header http.Header, body []byte) error
+}
+
+var registry = map[string]Provider{}
+
+// Register makes p available under p.Name(). It panics on duplicate names.
+func Register(p Provider) {
+	name := p.Name()
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("provider %s registered twice", name))
+	}
+	registry[name] = p
+}
+
+// Lookup returns the provider registered under name.
+func Lookup(name string) (Provider, bool) {
+	p, ok := registry[name]
+	return p, ok
+}
+
+// Names returns all registered protocol names in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decodeResponse unmarshals a complete response body.
+func decodeResponse(body []byte) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	return result, nil
+}
+
+// setBearer applies bearer token authentication.
+func setBearer(h http.Header, apiKey string) {
+	h.Set("Authorization", "Bearer "+apiKey)
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubProvider struct{ name string }
+
+func (s stubProvider) Name() string                                   { return s.name }
+func (stubProvider) Endpoint(req Request) string                      { return req.URL }
+func (stubProvider) Payload(Request) map[string]interface{}           { return nil }
+func (stubProvider) Headers(http.Header)                              {}
+func (stubProvider) Authorize(http.Header, string)                    {}
+func (stubProvider) ParseResponse([]byte) (string, error)             { return "", nil }
+func (stubProvider) ParseStream(io.Reader, io.Writer) (string, error) { return "", nil }
+func (stubProvider) DecodeError(status int, header http.Header, body []byte) error {
+	return decodeAPIError(status, header, body)
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, []string{
+		"anthropic",
+		"azure",
+		"gemini",
+		"ollama",
+		"ollama-chat",
+		"openai",
+		"responses",
+	}, Names())
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range Names() {
+		p, ok := Lookup(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, name, p.Name())
+	}
+
+	_, ok := Lookup("invalid")
+	assert.False(t, ok)
+}
+
+func TestRegister(t *testing.T) {
+	Register(stubProvider{name: "stub"})
+	defer delete(registry, "stub")
+
+	p, ok := Lookup("stub")
+	assert.True(t, ok)
+	assert.Equal(t, "stub", p.Name())
+
+	assert.Panics(t, func() { Register(stubProvider{name: "stub"}) })
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name string
+		want map[string]string
+	}{
+		{name: "openai", want: map[string]string{"Authorization": "Bearer key"}},
+		{name: "ollama", want: map[string]string{"Authorization": "Bearer key"}},
+		{name: "ollama-chat", want: map[string]string{"Authorization": "Bearer key"}},
+		{name: "responses", want: map[string]string{"Authorization": "Bearer key"}},
+		{name: "anthropic", want: map[string]string{"X-Api-Key": "key"}},
+		{name: "azure", want: map[string]string{"Api-Key": "key"}},
+		{name: "gemini", want: map[string]string{"X-Goog-Api-Key": "key"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := Lookup(tt.name)
+			h := http.Header{}
+			p.Authorize(h, "key")
+
+			assert.Len(t, h, len(tt.want))
+			for k, v := range tt.want {
+				assert.Equal(t, v, h.Get(k))
+			}
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			p, _ := Lookup(name)
+			h := http.Header{}
+			p.Headers(h)
+
+			if name == "anthropic" {
+				assert.Equal(t, http.Header{"Anthropic-Version": {anthropicVersion}}, h)
+			} else {
+				assert.Empty(t, h)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// responsesParams has no seed or stop sequences.
+var responsesParams = paramFields{
+	temperature: "temperature",
+	topP:        "top_p",
+	maxTokens:   "max_output_tokens",
+}
+
+// responses implements the OpenAI Responses API.
+type responses struct{}
+
+func init() { Register(responses{}) }
+
+func (responses) Name() string { return "responses" }
+
+func (responses) Endpoint(req Request) string { return req.URL }
+
+func (responses) Payload(req Request) map[string]interface{} {
+	payload := map[string]interface{}{
+		"model": req.Model,
+		"input": req.Query,
+	}
+	if req.System != "" {
+		payload["instructions"] = req.System
+	}
+	responsesParams.set(payload, req.Params)
+	if req.Stream {
+		payload["stream"] = true
+	}
+	return payload
+}
+
+func (responses) Headers(http.Header) {}
+
+func (responses) Authorize(h http.Header, apiKey string) { setBearer(h, apiKey) }
+
+// ParseResponse joins the output_text parts of every message item.
+// Reasoning and tool items are skipped.
+func (responses) ParseResponse(body []byte) (string, error) {
+	result, err := decodeResponse(body)
+	if err != nil {
+		return "", err
+	}
+
+	items, ok := result["output"].([]interface{})
+	if !ok {
+		return "", fmt.Errorf("no output in responses response")
+	}
+
+	var text strings.Builder
+	found := false
+	for _, i := range items {
+		item, ok := i.(map[string]interface{})
+		if !ok || item["type"] != "message" {
+			continue
+		}
+		parts, _ := item["content"].([]interface{})
+		for _, p := range parts {
+			part, ok := p.(map[string]interface{})
+			if !ok || part["type"] != "output_text" {
+				continue
+			}
+			if s, ok := part["text"].(string); ok {
+				text.WriteString(s)
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		return "", fmt.Errorf("no output_text in responses response")
+	}
+
+	return text.String(), nil
+}
+
+func (responses) ParseStream(body io.Reader, w io.Writer) (string, error) {
+	return readSSE(body, w, parseResponsesEvent)
+}
+
+func (responses) DecodeError(status int, header http.Header, body []byte) error {
+	return decodeAPIError(status, header, body)
+}
+
+// parseResponsesEvent extracts output text deltas from a single stream event.
+// Reasoning summaries and other event types are skipped.
+func parseResponsesEvent(data []byte) (string, bool, error) {
+	event, err := decodeChunk(data)
+	if err != nil {
+		return "", false, err
+	}
+
+	switch event["type"] {
+	case "response.output_text.delta":
+		delta, _ := event["delta"].(string)
+		return delta, false, nil
+	case "response.completed", "response.incomplete":
+		return "", true, nil
+	case "response.failed":
+		response, _ := event["response"].(map[string]interface{})
+		return "", false, streamError(response["error"])
+	case "error":
+		return "", false, streamError(event)
+	}
+
+	return "", false, nil
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxStreamLine bounds a single SSE or NDJSON line.
+const maxStreamLine = 1024 * 1024
+
+// chunkParser extracts content from a single stream payload and reports
+// whether it marks the end of the stream.
+type chunkParser func(data []byte) (string, bool, error)
+
+// readSSE consumes server-sent events until the parser reports completion,
+// a [DONE] marker, or end of stream.
+func readSSE(body io.Reader, w io.Writer, parse chunkParser) (string, error) {
+	var full strings.Builder
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLine)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		text, done, err := parse([]byte(data))
+		if err != nil {
+			return full.String(), err
+		}
+		if err := emit(w, &full, text); err != nil {
+			return full.String(), err
+		}
+		if done {
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return full.String(), fmt.Errorf("read stream: %w", err)
+	}
+
+	return full.String(), nil
+}
+
+// readNDJSON consumes newline-delimited JSON chunks until the parser reports
+// completion or end of stream.
+func readNDJSON(body io.Reader, w io.Writer, parse chunkParser) (string, error) {
+	var full strings.Builder
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLine)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		text, done, err := parse([]byte(line))
+		if err != nil {
+			return full.String(), err
+		}
+		if err := emit(w, &full, text); err != nil {
+			return full.String(), err
+		}
+		if done {
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return full.String(), fmt.Errorf("read stream: %w", err)
+	}
+
+	return full.String(), nil
+}
+
+// decodeChunk unmarshals a single stream payload.
+func decodeChunk(data []byte) (map[string]interface{}, error) {
+	var chunk map[string]interface{}
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return nil, fmt.Errorf("parse stream chunk: %w", err)
+	}
+	return chunk, nil
+}
+
+// streamError reports an error object embedded in a stream.
+func streamError(errObj interface{}) error {
+	errJSON, _ := json.Marshal(errObj)
+	return fmt.Errorf("stream error: %s", string(errJSON))
+}
+
+// emit writes text to w and records it in full.
+func emit(w io.Writer, full *strings.Builder, text string) error {
+	if text == "" {
+		return nil
+	}
+	full.WriteString(text)
+	if _, err := io.WriteString(w, text); err != nil {
+		return fmt.Errorf("write stream: %w", err)
+	}
+	return nil
+}